@@ -24,18 +24,6 @@ type candidate struct {
 	lineNumber int
 }
 
-func clean(dirty []byte) string {
-	var result strings.Builder
-	for i, b := range dirty {
-		if (b >= 0x20 && b <= 0x7e) || b == ' ' {
-			result.Write(dirty[i : i+1])
-		} else {
-			fmt.Fprintf(&result, "<0x%x>", b)
-		}
-	}
-	return result.String()
-}
-
 func main() {
 	file, err := os.Open(inputFile)
 	if err != nil {
@@ -99,6 +87,10 @@ func main() {
 		return candidates[i].variance < candidates[j].variance
 	})
 	for _, c := range candidates {
-		fmt.Printf("%v: (%q) [%v] %s\n", c.lineNumber, string([]byte{c.key}), c.variance, clean([]byte(c.text)))
+		fmt.Printf("%v: (%q) [%v]\n", c.lineNumber, string([]byte{c.key}), c.variance)
+		if _, err := io.Copy(os.Stdout, encoding.HexDump(strings.NewReader(c.text), encoding.HexDumpOptions{})); err != nil {
+			fmt.Printf("error dumping text: %v\n", err)
+			return
+		}
 	}
 }
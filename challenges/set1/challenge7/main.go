@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"cryptopals/utils/aes"
 	"cryptopals/utils/encoding"
+	"cryptopals/utils/padding"
 	"fmt"
 	"io"
 	"os"
@@ -21,7 +22,7 @@ func main() {
 	}
 	defer file.Close()
 
-	decoder := encoding.Base64Decoder(file)
+	decoder := encoding.Base64Decoder(file, encoding.SkipWhitespace())
 	inputData := bytes.NewBuffer([]byte{})
 	_, err = io.Copy(inputData, decoder)
 	if err != nil {
@@ -33,7 +34,7 @@ func main() {
 	copy(keyBytes[:], []byte("YELLOW SUBMARINE"))
 	key := aes.NewKey128(keyBytes)
 
-	decryptor := aes.Ecb(key, false, inputData)
+	decryptor := aes.Ecb(key, false, inputData, aes.WithPadding(padding.PKCS7))
 	decryptedData := bytes.NewBuffer([]byte{})
 	_, err = io.Copy(decryptedData, decryptor)
 	if err != nil {
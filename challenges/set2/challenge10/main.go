@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"cryptopals/utils/aes"
 	"cryptopals/utils/encoding"
+	"cryptopals/utils/padding"
 	"fmt"
 	"io"
 	"os"
@@ -21,7 +22,7 @@ func main() {
 	}
 	defer file.Close()
 
-	decoder := encoding.Base64Decoder(file)
+	decoder := encoding.Base64Decoder(file, encoding.SkipWhitespace())
 	inputData := bytes.NewBuffer([]byte{})
 	_, err = io.Copy(inputData, decoder)
 	if err != nil {
@@ -34,7 +35,7 @@ func main() {
 	key := aes.NewKey128(keyBytes)
 
 	var iv [16]byte
-	decryptor := aes.Cbc(key, iv, false, inputData)
+	decryptor := aes.Cbc(key, iv, false, inputData, aes.WithPadding(padding.PKCS7))
 	decryptedData := bytes.NewBuffer([]byte{})
 	_, err = io.Copy(decryptedData, decryptor)
 	if err != nil {
@@ -75,25 +75,25 @@ func ebcOrCbcSandwich(input []byte) (*mysteryCiphertext, error) {
 	if _, err := io.Copy(combinedBuf, combined); err != nil {
 		return nil, err
 	}
-
-	paddedBuf := bytes.NewBuffer(make([]byte, 0))
-	if _, err := io.Copy(paddedBuf, padding.Pkcs7(16, combinedBuf)); err != nil {
-		return nil, err
-	}
 	if *verbose {
+		// The engines pad as they encrypt, so pad a copy of the plaintext to show what they see
+		paddedBuf := bytes.NewBuffer(make([]byte, 0))
+		if _, err := io.Copy(paddedBuf, padding.PKCS7.Pad(16, bytes.NewReader(combinedBuf.Bytes()))); err != nil {
+			return nil, err
+		}
 		fmt.Printf("Padded buffer: %v\n", paddedBuf.Bytes())
 	}
 
 	var encryptor io.Reader
 	wasEcb := false
 	if rand.Intn(2) == 1 {
-		encryptor = aes.Ecb(key, true, paddedBuf)
+		encryptor = aes.Ecb(key, true, combinedBuf, aes.WithPadding(padding.PKCS7))
 		wasEcb = true
 		if *verbose {
 			fmt.Printf("Is ECB\n")
 		}
 	} else {
-		encryptor = aes.Cbc(key, iv, true, paddedBuf)
+		encryptor = aes.Cbc(key, iv, true, combinedBuf, aes.WithPadding(padding.PKCS7))
 		if *verbose {
 			fmt.Printf("Is CBC\n")
 		}
@@ -1,7 +1,6 @@
 package aes
 
 import (
-	"bytes"
 	"cryptopals/utils/channels"
 	"fmt"
 	"io"
@@ -61,6 +60,47 @@ func NewKey128(data [16]byte) Key128 {
 	return Key128{data: data}
 }
 
+// Key192 represents an AES-192 key.
+type Key192 struct {
+	data [24]byte
+}
+
+// NewKey192 creates an AES-192 key with the given data.
+func NewKey192(data [24]byte) Key192 {
+	return Key192{data: data}
+}
+
+// Key256 represents an AES-256 key.
+type Key256 struct {
+	data [32]byte
+}
+
+// NewKey256 creates an AES-256 key with the given data.
+func NewKey256(data [32]byte) Key256 {
+	return Key256{data: data}
+}
+
+// NewKey creates an AES key of the appropriate size for the given data, which must be 16, 24 or 32
+// bytes long.
+func NewKey(data []byte) (Key, error) {
+	switch len(data) {
+	case 16:
+		var k [16]byte
+		copy(k[:], data)
+		return NewKey128(k), nil
+	case 24:
+		var k [24]byte
+		copy(k[:], data)
+		return NewKey192(k), nil
+	case 32:
+		var k [32]byte
+		copy(k[:], data)
+		return NewKey256(k), nil
+	default:
+		return nil, fmt.Errorf("invalid AES key length %d", len(data))
+	}
+}
+
 func makeKey(w [4]uint32) [16]byte {
 	var r [16]byte
 	for i := 0; i < 16; i++ {
@@ -84,32 +124,31 @@ func rotWord(w uint32) uint32 {
 	return w<<8 + w>>24
 }
 
-// KeySchedule returns the key schedule for an AES-128 key.
-func (k Key128) KeySchedule() chan [16]byte {
+// keySchedule runs the FIPS-197 key expansion over a 16, 24 or 32 byte key. The returned channel
+// first gets the initial round key, then all the following round keys (11, 13 or 15 in total).
+func keySchedule(key []byte) chan [16]byte {
 	s := make(chan [16]byte)
 	go func() {
-		var w [4]uint32
-		for i := 0; i < 16; i++ {
+		nk := len(key) / 4
+		rounds := nk + 6
+		w := make([]uint32, 4*(rounds+1))
+		for i := 0; i < len(key); i++ {
 			w[i/4] <<= 8
-			w[i/4] += (uint32)(k.data[i])
+			w[i/4] += (uint32)(key[i])
 		}
-		s <- makeKey(w)
 		rc := (byte)(0x01)
-		for i := 0; i < 10; i++ {
-			var w2 [4]uint32
-			w2[0] = w[0] ^ subWord(rotWord(w[3])) ^ ((uint32)(rc) << 24)
-			w2[1] = w[1] ^ w2[0]
-			w2[2] = w[2] ^ w2[1]
-			w2[3] = w[3] ^ w2[2]
-
-			s <- makeKey(w2)
-
-			t := rc&0x80 != 0
-			rc <<= 1
-			if t {
-				rc ^= 0x1b
+		for i := nk; i < len(w); i++ {
+			t := w[i-1]
+			if i%nk == 0 {
+				t = subWord(rotWord(t)) ^ ((uint32)(rc) << 24)
+				rc = gfmult(2, rc)
+			} else if nk > 6 && i%nk == 4 {
+				t = subWord(t)
 			}
-			w = w2
+			w[i] = w[i-nk] ^ t
+		}
+		for i := 0; i < len(w); i += 4 {
+			s <- makeKey([4]uint32{w[i], w[i+1], w[i+2], w[i+3]})
 		}
 		close(s)
 	}()
@@ -117,6 +156,21 @@ func (k Key128) KeySchedule() chan [16]byte {
 	return s
 }
 
+// KeySchedule returns the key schedule for an AES-128 key.
+func (k Key128) KeySchedule() chan [16]byte {
+	return keySchedule(k.data[:])
+}
+
+// KeySchedule returns the key schedule for an AES-192 key.
+func (k Key192) KeySchedule() chan [16]byte {
+	return keySchedule(k.data[:])
+}
+
+// KeySchedule returns the key schedule for an AES-256 key.
+func (k Key256) KeySchedule() chan [16]byte {
+	return keySchedule(k.data[:])
+}
+
 // AddRoundKey adds the given round key into the state.
 func AddRoundKey(state *[16]byte, roundKey *[16]byte) {
 	for i := 0; i < 16; i++ {
@@ -196,126 +250,352 @@ func MixColumns(state *[16]byte, encrypt bool) {
 	mixColumn([4]*byte{&state[12], &state[13], &state[14], &state[15]}, encrypt)
 }
 
-func reverse(c chan [16]byte) chan [16]byte {
-	r := make(chan [16]byte)
+// Implementation represents a choice of code path for the Rijndael block operation.
+type Implementation int
+
+const (
+	// TABLE uses precomputed T-tables which combine SubBytes, ShiftRows and MixColumns. It is the
+	// default.
+	TABLE Implementation = iota
+	// REFERENCE runs AddRoundKey, SubBytes, ShiftRows and MixColumns one step at a time.
+	REFERENCE
+	// CONSTANTTIME computes the s-box and MixColumns arithmetically, with no table lookups or
+	// branches on the state or round keys. Note that the key schedule itself still comes from
+	// Key.KeySchedule.
+	CONSTANTTIME
+)
 
-	go func() {
-		stack := make([][16]byte, 0)
-		for x := range c {
-			stack = append(stack, x)
-		}
-		for i := len(stack) - 1; i >= 0; i-- {
-			r <- stack[i]
-		}
-		close(r)
-	}()
+// implementationKey is a Key which carries a choice of Implementation.
+type implementationKey struct {
+	Key
+	impl Implementation
+}
 
-	return r
+// WithImplementation returns a Key with the same key schedule as key, which makes Rijndael (and
+// every engine built on it) use the given implementation.
+func WithImplementation(key Key, impl Implementation) Key {
+	if k, ok := key.(implementationKey); ok {
+		key = k.Key
+	}
+	return implementationKey{Key: key, impl: impl}
 }
 
-// Rijndael performs a raw AES operation on a single block of data.
-func Rijndael(key Key, encrypt bool, data [16]byte) (*[16]byte, error) {
+// rijndaelReference performs a raw AES operation on a single block of data by running each step
+// of the cipher in turn. roundKeys are in encryption order and must hold at least two round keys.
+// If trace is not nil, it is called with the state after every step.
+func rijndaelReference(roundKeys [][16]byte, encrypt bool, data [16]byte, trace Tracer) [16]byte {
 	state := data
-	var schedule chan [16]byte
-	if encrypt {
-		schedule = key.KeySchedule()
-	} else {
-		schedule = reverse(key.KeySchedule())
-	}
-	firstRoundKey, ok := <-schedule
-	if !ok {
-		return nil, fmt.Errorf("key had empty key schedule")
+	last := len(roundKeys) - 1
+	step := func(round int, s Step) {
+		if trace != nil {
+			trace(round, s, state)
+		}
 	}
 
-	AddRoundKey(&state, &firstRoundKey)
-
 	if encrypt {
-		if roundKey, ok := <-schedule; ok {
-			for {
-				nextRoundKey, ok := <-schedule
-				finalRound := !ok
-
-				SubBytes(&state, true)
-				ShiftRows(&state, true)
-				if !finalRound {
-					MixColumns(&state, true)
-				}
-				AddRoundKey(&state, &roundKey)
-
-				if finalRound {
-					break
-				}
-
-				roundKey = nextRoundKey
+		AddRoundKey(&state, &roundKeys[0])
+		step(0, ADDROUNDKEY)
+		for r := 1; r <= last; r++ {
+			SubBytes(&state, true)
+			step(r, SUBBYTES)
+			ShiftRows(&state, true)
+			step(r, SHIFTROWS)
+			if r != last {
+				MixColumns(&state, true)
+				step(r, MIXCOLUMNS)
 			}
+			AddRoundKey(&state, &roundKeys[r])
+			step(r, ADDROUNDKEY)
 		}
 	} else {
-		firstRound := true
-		for roundKey := range schedule {
-			if !firstRound {
-				MixColumns(&state, false)
-			}
+		AddRoundKey(&state, &roundKeys[last])
+		step(0, ADDROUNDKEY)
+		for r := last - 1; r >= 0; r-- {
 			ShiftRows(&state, false)
+			step(last-r, SHIFTROWS)
 			SubBytes(&state, false)
-			AddRoundKey(&state, &roundKey)
-
-			firstRound = false
+			step(last-r, SUBBYTES)
+			AddRoundKey(&state, &roundKeys[r])
+			step(last-r, ADDROUNDKEY)
+			if r != 0 {
+				MixColumns(&state, false)
+				step(last-r, MIXCOLUMNS)
+			}
 		}
 	}
 
+	return state
+}
+
+// Rijndael performs a raw AES operation on a single block of data. Unless key is an ExpandedKey,
+// the key schedule is computed from scratch on every call, so callers processing more than one
+// block should Expand the key first.
+func Rijndael(key Key, encrypt bool, data [16]byte) (*[16]byte, error) {
+	expanded, err := Expand(key)
+	if err != nil {
+		return nil, err
+	}
+	state := expanded.Crypt(encrypt, data)
 	return &state, nil
 }
 
+// readBlock reads a whole block from r however the reader splits the data up. It returns io.EOF at
+// the end of the data, and an error if the data ends part way through a block.
+func readBlock(r io.Reader, buf *[16]byte) error {
+	count, err := io.ReadFull(r, buf[:])
+	if err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("data is not a multiple of 16 bytes: %d bytes left over", count)
+	}
+	return err
+}
+
 // Ecb creates an AES-ECB encryption/decryption engine (depends on the value of `encrypt`) using the
-// supplied key.
-func Ecb(key Key, encrypt bool, r io.Reader) *channels.Reader {
+// supplied key. Unless the WithPadding option is given, the data must be a multiple of 16 bytes.
+func Ecb(key Key, encrypt bool, r io.Reader, opts ...Option) *channels.Reader {
 	o := make(chan byte)
 	e := make(chan error)
+	opt := makeOptions(opts)
+	if encrypt && opt.padding != nil {
+		r = opt.padding.Pad(16, r)
+	}
+	out := newBlockOutput(o, encrypt, opt)
 
 	// Create a goroutine which encrypts/decrypts data block by block.
 	go func() {
 		defer close(e)
 		defer close(o)
+		expanded, err := Expand(key)
+		if err != nil {
+			e <- err
+			return
+		}
 		var buf [16]byte
 		for {
-			count, err := r.Read(buf[:])
-			if count != 0 {
-				if count < 16 {
-					e <- fmt.Errorf("padding not supported - supply a multiple of 16 bytes of data")
-					return
-				}
-				output, err := Rijndael(key, encrypt, buf)
-				if err != nil {
-					e <- err
-					return
-				}
-				for _, b := range output {
-					o <- b
+			if err := readBlock(r, &buf); err != nil {
+				if err == io.EOF {
+					if padErr := out.finish(); padErr != nil {
+						e <- padErr
+						return
+					}
 				}
+				e <- err
+				return
 			}
+			output, err := Rijndael(expanded, encrypt, buf)
 			if err != nil {
 				e <- err
 				return
 			}
+			out.send(*output)
 		}
 	}()
 
 	return channels.NewReader(o, e)
 }
 
+// cbcChain encrypts or decrypts a run of CBC blocks, carrying the chaining value from one block to
+// the next.
+type cbcChain struct {
+	key  *ExpandedKey
+	prev [16]byte
+}
+
+// crypt encrypts or decrypts the next block in the chain.
+func (c *cbcChain) crypt(encrypt bool, block [16]byte) [16]byte {
+	if encrypt {
+		for i := range block {
+			block[i] ^= c.prev[i]
+		}
+		c.prev = c.key.Encrypt(block)
+		return c.prev
+	}
+	output := c.key.Decrypt(block)
+	for i := range output {
+		output[i] ^= c.prev[i]
+	}
+	c.prev = block
+	return output
+}
+
 // Cbc creates an AES-CBC encryption/decryption engine (depends on the value of `encrypt`) using the
-// supplied key.
+// supplied key. Unless the WithPadding option is given, the data must be a multiple of 16 bytes.
 // TODO: Make this fancy-elegant by building it out of ECB with some stuff from the io package
 // (without introducing deadlock)
-func Cbc(key Key, iv [16]byte, encrypt bool, r io.Reader) io.Reader {
+func Cbc(key Key, iv [16]byte, encrypt bool, r io.Reader, opts ...Option) io.Reader {
 	o := make(chan byte)
 	e := make(chan error)
-	ivBuf := bytes.NewBuffer(iv[:])
+	opt := makeOptions(opts)
+	if encrypt && opt.padding != nil {
+		r = opt.padding.Pad(16, r)
+	}
+	out := newBlockOutput(o, encrypt, opt)
 
 	// Create a goroutine which encrypts/decrypts data block by block.
 	go func() {
 		defer close(e)
 		defer close(o)
+		expanded, err := Expand(key)
+		if err != nil {
+			e <- err
+			return
+		}
+		chain := cbcChain{key: expanded, prev: iv}
+		var buf [16]byte
+		for {
+			if err := readBlock(r, &buf); err != nil {
+				if err == io.EOF {
+					if padErr := out.finish(); padErr != nil {
+						e <- padErr
+						return
+					}
+				}
+				e <- err
+				return
+			}
+			out.send(chain.crypt(encrypt, buf))
+		}
+	}()
+
+	return channels.NewReader(o, e)
+}
+
+// CfbMode represents the segment size used in CFB mode.
+type CfbMode int
+
+const (
+	// CFB8 feeds back one byte of ciphertext per block operation.
+	CFB8 CfbMode = iota
+	// CFB128 feeds back a whole block of ciphertext per block operation.
+	CFB128
+)
+
+// Cfb creates an AES-CFB encryption/decryption engine (depends on the value of `encrypt`) using the
+// supplied key and segment size. CFB is a stream mode, so the data does not need to be a multiple
+// of 16 bytes.
+func Cfb(key Key, iv [16]byte, mode CfbMode, encrypt bool, r io.Reader) *channels.Reader {
+	o := make(chan byte)
+	e := make(chan error)
+
+	// Create a goroutine which encrypts/decrypts data byte by byte, feeding the ciphertext back into
+	// the shift register.
+	go func() {
+		defer close(e)
+		defer close(o)
+		if mode < CFB8 || mode > CFB128 {
+			e <- fmt.Errorf("invalid CFB mode %d", mode)
+			return
+		}
+		expanded, err := Expand(key)
+		if err != nil {
+			e <- err
+			return
+		}
+		register := iv
+		var keystream *[16]byte
+		var buf [16]byte
+		idx := 0
+		for {
+			count, err := r.Read(buf[:])
+			for _, b := range buf[:count] {
+				if keystream == nil {
+					var ksErr error
+					keystream, ksErr = Rijndael(expanded, true, register)
+					if ksErr != nil {
+						e <- ksErr
+						return
+					}
+				}
+				out := b ^ keystream[idx]
+				c := out
+				if !encrypt {
+					c = b
+				}
+				switch mode {
+				case CFB8:
+					copy(register[:], register[1:])
+					register[15] = c
+					keystream = nil
+				case CFB128:
+					register[idx] = c
+					idx++
+					if idx == 16 {
+						keystream = nil
+						idx = 0
+					}
+				}
+				o <- out
+			}
+			if err != nil {
+				e <- err
+				return
+			}
+		}
+	}()
+
+	return channels.NewReader(o, e)
+}
+
+// Ofb creates an AES-OFB encryption/decryption engine using the supplied key. OFB is a stream mode,
+// so encryption and decryption are the same operation and `encrypt` only exists for symmetry with
+// the other engines.
+func Ofb(key Key, iv [16]byte, encrypt bool, r io.Reader) *channels.Reader {
+	o := make(chan byte)
+	e := make(chan error)
+
+	// Create a goroutine which XORs the data with the keystream as it becomes available.
+	go func() {
+		defer close(e)
+		defer close(o)
+		expanded, err := Expand(key)
+		if err != nil {
+			e <- err
+			return
+		}
+		keystream := &iv
+		var buf [16]byte
+		idx := 16
+		for {
+			count, err := r.Read(buf[:])
+			for _, b := range buf[:count] {
+				if idx == 16 {
+					var ksErr error
+					keystream, ksErr = Rijndael(expanded, true, *keystream)
+					if ksErr != nil {
+						e <- ksErr
+						return
+					}
+					idx = 0
+				}
+				o <- b ^ keystream[idx]
+				idx++
+			}
+			if err != nil {
+				e <- err
+				return
+			}
+		}
+	}()
+
+	return channels.NewReader(o, e)
+}
+
+// Pcbc creates an AES-PCBC encryption/decryption engine (depends on the value of `encrypt`) using
+// the supplied key. Unlike CBC, both the plaintext and ciphertext of each block are chained into the
+// next one.
+func Pcbc(key Key, iv [16]byte, encrypt bool, r io.Reader) *channels.Reader {
+	o := make(chan byte)
+	e := make(chan error)
+
+	// Create a goroutine which encrypts/decrypts data block by block.
+	go func() {
+		defer close(e)
+		defer close(o)
+		expanded, err := Expand(key)
+		if err != nil {
+			e <- err
+			return
+		}
+		chain := iv
 		var buf [16]byte
 		for {
 			count, err := r.Read(buf[:])
@@ -325,43 +605,30 @@ func Cbc(key Key, iv [16]byte, encrypt bool, r io.Reader) io.Reader {
 					return
 				}
 
+				input := buf
 				if encrypt {
-					// XOR-in the next IV block
-					var ivBlock [16]byte
-					_, err = ivBuf.Read(ivBlock[:])
-					if err != nil {
-						e <- err
-						return
+					for i := range input {
+						input[i] ^= chain[i]
 					}
-					for i := range buf {
-						buf[i] ^= ivBlock[i]
-					}
-				} else {
-					// Append the IV buffer
-					ivBuf.Write(buf[:])
 				}
 
-				output, err := Rijndael(key, encrypt, buf)
+				output, err := Rijndael(expanded, encrypt, input)
 				if err != nil {
 					e <- err
 					return
 				}
 
-				if encrypt {
-					// Append the IV buffer
-					ivBuf.Write(output[:])
-				} else {
-					// XOR-in the next IV block
-					var ivBlock [16]byte
-					_, err = ivBuf.Read(ivBlock[:])
-					if err != nil {
-						e <- err
-						return
-					}
+				if !encrypt {
 					for i := range output {
-						output[i] ^= ivBlock[i]
+						output[i] ^= chain[i]
 					}
 				}
+
+				// The next block is chained with plaintext XOR ciphertext
+				for i := range chain {
+					chain[i] = buf[i] ^ output[i]
+				}
+
 				for _, b := range output {
 					o <- b
 				}
@@ -170,3 +170,245 @@ func TestCbc(t *testing.T) {
 		t.Errorf("Want %v got %v", expected, encrypted.Bytes())
 	}
 }
+
+func TestKeyScheduleLength(t *testing.T) {
+	cases := []struct {
+		name    string
+		key     []byte
+		rounds  int
+		lastKey [16]byte
+	}{
+		{
+			name:    "AES128",
+			key:     []byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c},
+			rounds:  11,
+			lastKey: [16]byte{0xd0, 0x14, 0xf9, 0xa8, 0xc9, 0xee, 0x25, 0x89, 0xe1, 0x3f, 0x0c, 0xc8, 0xb6, 0x63, 0x0c, 0xa6},
+		},
+		{
+			name: "AES192",
+			key: []byte{0x8e, 0x73, 0xb0, 0xf7, 0xda, 0x0e, 0x64, 0x52, 0xc8, 0x10, 0xf3, 0x2b, 0x80, 0x90, 0x79, 0xe5,
+				0x62, 0xf8, 0xea, 0xd2, 0x52, 0x2c, 0x6b, 0x7b},
+			rounds:  13,
+			lastKey: [16]byte{0xe9, 0x8b, 0xa0, 0x6f, 0x44, 0x8c, 0x77, 0x3c, 0x8e, 0xcc, 0x72, 0x04, 0x01, 0x00, 0x22, 0x02},
+		},
+		{
+			name: "AES256",
+			key: []byte{0x60, 0x3d, 0xeb, 0x10, 0x15, 0xca, 0x71, 0xbe, 0x2b, 0x73, 0xae, 0xf0, 0x85, 0x7d, 0x77, 0x81,
+				0x1f, 0x35, 0x2c, 0x07, 0x3b, 0x61, 0x08, 0xd7, 0x2d, 0x98, 0x10, 0xa3, 0x09, 0x14, 0xdf, 0xf4},
+			rounds:  15,
+			lastKey: [16]byte{0xfe, 0x48, 0x90, 0xd1, 0xe6, 0x18, 0x8d, 0x0b, 0x04, 0x6d, 0xf3, 0x44, 0x70, 0x6c, 0x63, 0x1e},
+		},
+	}
+
+	for _, c := range cases {
+		// loop variable c will be captured by reference, so we shadow it with a new variable also
+		// called c
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			key, err := aes.NewKey(c.key)
+			if err != nil {
+				t.Fatalf("Want nil got %v", err)
+			}
+			var roundKeys [][16]byte
+			for roundKey := range key.KeySchedule() {
+				roundKeys = append(roundKeys, roundKey)
+			}
+			if len(roundKeys) != c.rounds {
+				t.Fatalf("Want %d round keys got %d", c.rounds, len(roundKeys))
+			}
+			if !bytes.Equal(roundKeys[0][:], c.key[:16]) {
+				t.Errorf("Want %v got %v", c.key[:16], roundKeys[0])
+			}
+			last := roundKeys[len(roundKeys)-1]
+			if !bytes.Equal(last[:], c.lastKey[:]) {
+				t.Errorf("Want %v got %v", c.lastKey, last)
+			}
+		})
+	}
+}
+
+func TestNewKeyInvalidLength(t *testing.T) {
+	if _, err := aes.NewKey(make([]byte, 20)); err == nil {
+		t.Errorf("Want err got nil")
+	}
+}
+
+func TestRijndaelKeySizes(t *testing.T) {
+	data := [16]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}
+	cases := []struct {
+		name     string
+		key      aes.Key
+		expected [16]byte
+	}{
+		{
+			name:     "AES128",
+			key:      aes.NewKey128([16]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}),
+			expected: [16]byte{0x69, 0xc4, 0xe0, 0xd8, 0x6a, 0x7b, 0x04, 0x30, 0xd8, 0xcd, 0xb7, 0x80, 0x70, 0xb4, 0xc5, 0x5a},
+		},
+		{
+			name: "AES192",
+			key: aes.NewKey192([24]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+				0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17}),
+			expected: [16]byte{0xdd, 0xa9, 0x7c, 0xa4, 0x86, 0x4c, 0xdf, 0xe0, 0x6e, 0xaf, 0x70, 0xa0, 0xec, 0x0d, 0x71, 0x91},
+		},
+		{
+			name: "AES256",
+			key: aes.NewKey256([32]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+				0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18, 0x19, 0x1a, 0x1b, 0x1c, 0x1d, 0x1e, 0x1f}),
+			expected: [16]byte{0x8e, 0xa2, 0xb7, 0xca, 0x51, 0x67, 0x45, 0xbf, 0xea, 0xfc, 0x49, 0x90, 0x4b, 0x49, 0x60, 0x89},
+		},
+	}
+
+	for _, c := range cases {
+		// loop variable c will be captured by reference, so we shadow it with a new variable also
+		// called c
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := aes.Rijndael(c.key, true, data)
+			if err != nil {
+				t.Fatalf("Want nil got %v", err)
+			}
+			if !bytes.Equal(result[:], c.expected[:]) {
+				t.Errorf("Want %v got %v", c.expected, result)
+			}
+			result, err = aes.Rijndael(c.key, false, *result)
+			if err != nil {
+				t.Fatalf("Want nil got %v", err)
+			}
+			if !bytes.Equal(result[:], data[:]) {
+				t.Errorf("Want %v got %v", data, result)
+			}
+		})
+	}
+}
+
+// Test vectors from NIST SP 800-38A, Appendix F.
+var (
+	sp80038aKey = aes.NewKey128([16]byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c})
+	sp80038aIv  = [16]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	sp80038aPt  = []byte{0x6b, 0xc1, 0xbe, 0xe2, 0x2e, 0x40, 0x9f, 0x96, 0xe9, 0x3d, 0x7e, 0x11, 0x73, 0x93, 0x17, 0x2a,
+		0xae, 0x2d, 0x8a, 0x57, 0x1e, 0x03, 0xac, 0x9c, 0x9e, 0xb7, 0x6f, 0xac, 0x45, 0xaf, 0x8e, 0x51,
+		0x30, 0xc8, 0x1c, 0x46, 0xa3, 0x5c, 0xe4, 0x11, 0xe5, 0xfb, 0xc1, 0x19, 0x1a, 0x0a, 0x52, 0xef,
+		0xf6, 0x9f, 0x24, 0x45, 0xdf, 0x4f, 0x9b, 0x17, 0xad, 0x2b, 0x41, 0x7b, 0xe6, 0x6c, 0x37, 0x10}
+)
+
+func TestStreamModes(t *testing.T) {
+	cases := []struct {
+		name     string
+		engine   func(encrypt bool, r io.Reader) io.Reader
+		input    []byte
+		expected []byte
+	}{
+		{
+			// F.3.7 CFB8-AES128.Encrypt
+			name: "CFB8",
+			engine: func(encrypt bool, r io.Reader) io.Reader {
+				return aes.Cfb(sp80038aKey, sp80038aIv, aes.CFB8, encrypt, r)
+			},
+			input:    sp80038aPt[:18],
+			expected: []byte{0x3b, 0x79, 0x42, 0x4c, 0x9c, 0x0d, 0xd4, 0x36, 0xba, 0xce, 0x9e, 0x0e, 0xd4, 0x58, 0x6a, 0x4f, 0x32, 0xb9},
+		},
+		{
+			// F.3.13 CFB128-AES128.Encrypt
+			name: "CFB128",
+			engine: func(encrypt bool, r io.Reader) io.Reader {
+				return aes.Cfb(sp80038aKey, sp80038aIv, aes.CFB128, encrypt, r)
+			},
+			input: sp80038aPt,
+			expected: []byte{0x3b, 0x3f, 0xd9, 0x2e, 0xb7, 0x2d, 0xad, 0x20, 0x33, 0x34, 0x49, 0xf8, 0xe8, 0x3c, 0xfb, 0x4a,
+				0xc8, 0xa6, 0x45, 0x37, 0xa0, 0xb3, 0xa9, 0x3f, 0xcd, 0xe3, 0xcd, 0xad, 0x9f, 0x1c, 0xe5, 0x8b,
+				0x26, 0x75, 0x1f, 0x67, 0xa3, 0xcb, 0xb1, 0x40, 0xb1, 0x80, 0x8c, 0xf1, 0x87, 0xa4, 0xf4, 0xdf,
+				0xc0, 0x4b, 0x05, 0x35, 0x7c, 0x5d, 0x1c, 0x0e, 0xea, 0xc4, 0xc6, 0x6f, 0x9f, 0xf7, 0xf2, 0xe6},
+		},
+		{
+			// F.4.1 OFB-AES128.Encrypt
+			name: "OFB",
+			engine: func(encrypt bool, r io.Reader) io.Reader {
+				return aes.Ofb(sp80038aKey, sp80038aIv, encrypt, r)
+			},
+			input: sp80038aPt,
+			expected: []byte{0x3b, 0x3f, 0xd9, 0x2e, 0xb7, 0x2d, 0xad, 0x20, 0x33, 0x34, 0x49, 0xf8, 0xe8, 0x3c, 0xfb, 0x4a,
+				0x77, 0x89, 0x50, 0x8d, 0x16, 0x91, 0x8f, 0x03, 0xf5, 0x3c, 0x52, 0xda, 0xc5, 0x4e, 0xd8, 0x25,
+				0x97, 0x40, 0x05, 0x1e, 0x9c, 0x5f, 0xec, 0xf6, 0x43, 0x44, 0xf7, 0xa8, 0x22, 0x60, 0xed, 0xcc,
+				0x30, 0x4c, 0x65, 0x28, 0xf6, 0x59, 0xc7, 0x78, 0x66, 0xa5, 0x10, 0xd9, 0xc1, 0xd6, 0xae, 0x5e},
+		},
+	}
+
+	for _, c := range cases {
+		// loop variable c will be captured by reference, so we shadow it with a new variable also
+		// called c
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			encrypted := new(bytes.Buffer)
+			_, err := io.Copy(encrypted, c.engine(true, bytes.NewReader(c.input)))
+			if err != nil {
+				t.Errorf("Want nil got %v", err)
+			}
+			if !bytes.Equal(encrypted.Bytes(), c.expected) {
+				t.Errorf("Want %v got %v", c.expected, encrypted.Bytes())
+			}
+
+			// Now do it in reverse
+			decrypted := new(bytes.Buffer)
+			_, err = io.Copy(decrypted, c.engine(false, bytes.NewReader(c.expected)))
+			if err != nil {
+				t.Errorf("Want nil got %v", err)
+			}
+			if !bytes.Equal(decrypted.Bytes(), c.input) {
+				t.Errorf("Want %v got %v", c.input, decrypted.Bytes())
+			}
+		})
+	}
+}
+
+func TestCfbInvalidMode(t *testing.T) {
+	_, err := io.Copy(io.Discard, aes.Cfb(sp80038aKey, sp80038aIv, aes.CfbMode(9), true, bytes.NewReader(sp80038aPt)))
+	if err == nil {
+		t.Errorf("Want err got nil")
+	}
+}
+
+func TestPcbc(t *testing.T) {
+	// PCBC has no published vectors, so build the expected ciphertext out of single-block
+	// operations: C[i] = E(P[i] ^ P[i-1] ^ C[i-1]), with P[-1] ^ C[-1] = IV.
+	var expected []byte
+	chain := sp80038aIv
+	for i := 0; i < len(sp80038aPt); i += 16 {
+		var block [16]byte
+		copy(block[:], sp80038aPt[i:i+16])
+		input := block
+		for j := range input {
+			input[j] ^= chain[j]
+		}
+		output, err := aes.Rijndael(sp80038aKey, true, input)
+		if err != nil {
+			t.Fatalf("Want nil got %v", err)
+		}
+		for j := range chain {
+			chain[j] = block[j] ^ output[j]
+		}
+		expected = append(expected, output[:]...)
+	}
+
+	encrypted := new(bytes.Buffer)
+	_, err := io.Copy(encrypted, aes.Pcbc(sp80038aKey, sp80038aIv, true, bytes.NewReader(sp80038aPt)))
+	if err != nil {
+		t.Errorf("Want nil got %v", err)
+	}
+	if !bytes.Equal(encrypted.Bytes(), expected) {
+		t.Errorf("Want %v got %v", expected, encrypted.Bytes())
+	}
+
+	// Now do it in reverse
+	decrypted := new(bytes.Buffer)
+	_, err = io.Copy(decrypted, aes.Pcbc(sp80038aKey, sp80038aIv, false, bytes.NewReader(expected)))
+	if err != nil {
+		t.Errorf("Want nil got %v", err)
+	}
+	if !bytes.Equal(decrypted.Bytes(), sp80038aPt) {
+		t.Errorf("Want %v got %v", sp80038aPt, decrypted.Bytes())
+	}
+}
@@ -0,0 +1,78 @@
+package aes
+
+// Block adapts a Key to the crypto/cipher.Block interface, so that it can be used with the
+// standard library's modes of operation.
+type Block struct {
+	key *ExpandedKey
+}
+
+// NewBlock creates a Block which encrypts and decrypts with the given key.
+func NewBlock(key Key) (*Block, error) {
+	expanded, err := Expand(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Block{key: expanded}, nil
+}
+
+// BlockSize returns the AES block size, which is always 16 bytes.
+func (b *Block) BlockSize() int {
+	return 16
+}
+
+// Encrypt encrypts the first block in src into dst. dst and src may overlap entirely.
+func (b *Block) Encrypt(dst, src []byte) {
+	b.crypt(true, dst, src)
+}
+
+// Decrypt decrypts the first block in src into dst. dst and src may overlap entirely.
+func (b *Block) Decrypt(dst, src []byte) {
+	b.crypt(false, dst, src)
+}
+
+func (b *Block) crypt(encrypt bool, dst, src []byte) {
+	if len(src) < 16 {
+		panic("aes: input not full block")
+	}
+	if len(dst) < 16 {
+		panic("aes: output not full block")
+	}
+	var in [16]byte
+	copy(in[:], src)
+	out := b.key.Crypt(encrypt, in)
+	copy(dst, out[:])
+}
+
+// CtrStream adapts an AES-CTR keystream to the crypto/cipher.Stream interface.
+type CtrStream struct {
+	buf *CtrBuffer
+	off int64
+}
+
+// NewCtrStream creates a CtrStream using the supplied key, nonce and counter format.
+func NewCtrStream(key Key, nonce [16]byte, format CounterFormat) (*CtrStream, error) {
+	expanded, err := Expand(key)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := NewCtrBuffer(expanded, nonce, format, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &CtrStream{buf: buf}, nil
+}
+
+// XORKeyStream XORs each byte in src with the next byte of the keystream and writes it to dst.
+// dst and src may overlap entirely.
+func (s *CtrStream) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("aes: output smaller than input")
+	}
+	dst = dst[:len(src)]
+	copy(dst, src)
+	// The key is already expanded, so generating the keystream cannot fail
+	if err := s.buf.xorKeystreamAt(dst, s.off); err != nil {
+		panic(err)
+	}
+	s.off += int64(len(src))
+}
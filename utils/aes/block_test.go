@@ -0,0 +1,172 @@
+package aes_test
+
+import (
+	"bytes"
+	stdaes "crypto/aes"
+	"crypto/cipher"
+	"cryptopals/utils/aes"
+	"io"
+	"math/rand"
+	"testing"
+	"testing/iotest"
+)
+
+// Compile-time checks that the adapters implement the standard interfaces.
+var (
+	_ cipher.Block  = (*aes.Block)(nil)
+	_ cipher.Stream = (*aes.CtrStream)(nil)
+)
+
+func TestEcbMatchesStandardLibrary(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	for _, size := range []int{16, 24, 32} {
+		for i := 0; i < 20; i++ {
+			keyData := make([]byte, size)
+			rng.Read(keyData)
+			key, err := aes.NewKey(keyData)
+			if err != nil {
+				t.Fatalf("Want nil got %v", err)
+			}
+			std, err := stdaes.NewCipher(keyData)
+			if err != nil {
+				t.Fatalf("Want nil got %v", err)
+			}
+			message := make([]byte, 16*rng.Intn(8))
+			rng.Read(message)
+
+			expected := make([]byte, len(message))
+			for j := 0; j < len(message); j += 16 {
+				std.Encrypt(expected[j:], message[j:])
+			}
+
+			encrypted := new(bytes.Buffer)
+			if _, err := io.Copy(encrypted, aes.Ecb(key, true, bytes.NewReader(message))); err != nil {
+				t.Errorf("Want nil got %v", err)
+			}
+			if !bytes.Equal(encrypted.Bytes(), expected) {
+				t.Errorf("Key %x: want %x got %x", keyData, expected, encrypted.Bytes())
+			}
+
+			decrypted := new(bytes.Buffer)
+			if _, err := io.Copy(decrypted, aes.Ecb(key, false, bytes.NewReader(expected))); err != nil {
+				t.Errorf("Want nil got %v", err)
+			}
+			if !bytes.Equal(decrypted.Bytes(), message) {
+				t.Errorf("Key %x: want %x got %x", keyData, message, decrypted.Bytes())
+			}
+		}
+	}
+}
+
+func TestCbcMatchesStandardLibrary(t *testing.T) {
+	rng := rand.New(rand.NewSource(8))
+	for _, size := range []int{16, 24, 32} {
+		for i := 0; i < 20; i++ {
+			keyData := make([]byte, size)
+			rng.Read(keyData)
+			key, err := aes.NewKey(keyData)
+			if err != nil {
+				t.Fatalf("Want nil got %v", err)
+			}
+			std, err := stdaes.NewCipher(keyData)
+			if err != nil {
+				t.Fatalf("Want nil got %v", err)
+			}
+			var iv [16]byte
+			rng.Read(iv[:])
+			message := make([]byte, 16*rng.Intn(8))
+			rng.Read(message)
+
+			expected := make([]byte, len(message))
+			cipher.NewCBCEncrypter(std, iv[:]).CryptBlocks(expected, message)
+
+			encrypted := new(bytes.Buffer)
+			if _, err := io.Copy(encrypted, aes.Cbc(key, iv, true, bytes.NewReader(message))); err != nil {
+				t.Errorf("Want nil got %v", err)
+			}
+			if !bytes.Equal(encrypted.Bytes(), expected) {
+				t.Errorf("Key %x: want %x got %x", keyData, expected, encrypted.Bytes())
+			}
+
+			decrypted := new(bytes.Buffer)
+			if _, err := io.Copy(decrypted, aes.Cbc(key, iv, false, bytes.NewReader(expected))); err != nil {
+				t.Errorf("Want nil got %v", err)
+			}
+			if !bytes.Equal(decrypted.Bytes(), message) {
+				t.Errorf("Key %x: want %x got %x", keyData, message, decrypted.Bytes())
+			}
+		}
+	}
+}
+
+func TestBlockWithStandardModes(t *testing.T) {
+	rng := rand.New(rand.NewSource(9))
+	keyData := make([]byte, 32)
+	rng.Read(keyData)
+	key, err := aes.NewKey(keyData)
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	block, err := aes.NewBlock(key)
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	std, err := stdaes.NewCipher(keyData)
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	var nonce [16]byte
+	rng.Read(nonce[:])
+	message := make([]byte, 100)
+	rng.Read(message)
+
+	// CTR
+	expected := make([]byte, len(message))
+	cipher.NewCTR(std, nonce[:]).XORKeyStream(expected, message)
+	got := make([]byte, len(message))
+	cipher.NewCTR(block, nonce[:]).XORKeyStream(got, message)
+	if !bytes.Equal(got, expected) {
+		t.Errorf("CTR: want %x got %x", expected, got)
+	}
+
+	// Our own CTR engine behind cipher.StreamReader, fed one byte at a time
+	stream, err := aes.NewCtrStream(key, nonce, aes.NIST)
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	output := new(bytes.Buffer)
+	reader := cipher.StreamReader{S: stream, R: iotest.OneByteReader(bytes.NewReader(message))}
+	if _, err := io.Copy(output, reader); err != nil {
+		t.Errorf("Want nil got %v", err)
+	}
+	if !bytes.Equal(output.Bytes(), expected) {
+		t.Errorf("CtrStream: want %x got %x", expected, output.Bytes())
+	}
+
+	// GCM
+	stdGcm, err := cipher.NewGCM(std)
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	ourGcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	aad := []byte("additional data")
+	expected = stdGcm.Seal(nil, nonce[:12], message, aad)
+	got = ourGcm.Seal(nil, nonce[:12], message, aad)
+	if !bytes.Equal(got, expected) {
+		t.Errorf("GCM: want %x got %x", expected, got)
+	}
+	gcm, err := aes.NewGcm(key, 16)
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	got, err = gcm.Seal(nonce[:12], message, aad)
+	if err != nil {
+		t.Errorf("Want nil got %v", err)
+	}
+	if !bytes.Equal(got, expected) {
+		t.Errorf("aes.Gcm: want %x got %x", expected, got)
+	}
+}
@@ -0,0 +1,346 @@
+package aes_test
+
+import (
+	"bufio"
+	"bytes"
+	"cryptopals/utils/aes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// cavpVector is a single COUNT record from a NIST CAVP response (.rsp) file.
+type cavpVector struct {
+	encrypt bool
+	fields  map[string]string
+}
+
+// bytes decodes the named hex field of the vector.
+func (v cavpVector) bytes(t *testing.T, name string) []byte {
+	t.Helper()
+	field, ok := v.fields[name]
+	if !ok {
+		t.Fatalf("COUNT %s: missing field %s", v.fields["COUNT"], name)
+	}
+	b, err := hex.DecodeString(field)
+	if err != nil {
+		t.Fatalf("COUNT %s: bad field %s: %v", v.fields["COUNT"], name, err)
+	}
+	return b
+}
+
+// block decodes the named hex field of the vector as a single block.
+func (v cavpVector) block(t *testing.T, name string) [16]byte {
+	t.Helper()
+	var r [16]byte
+	b := v.bytes(t, name)
+	if len(b) != 16 {
+		t.Fatalf("COUNT %s: field %s is %d bytes", v.fields["COUNT"], name, len(b))
+	}
+	copy(r[:], b)
+	return r
+}
+
+// key decodes the KEY field of the vector.
+func (v cavpVector) key(t *testing.T) aes.Key {
+	t.Helper()
+	key, err := aes.NewKey(v.bytes(t, "KEY"))
+	if err != nil {
+		t.Fatalf("COUNT %s: %v", v.fields["COUNT"], err)
+	}
+	return key
+}
+
+// input and output return the names of the input and output fields for the vector's direction.
+func (v cavpVector) input() string {
+	if v.encrypt {
+		return "PLAINTEXT"
+	}
+	return "CIPHERTEXT"
+}
+
+func (v cavpVector) output() string {
+	if v.encrypt {
+		return "CIPHERTEXT"
+	}
+	return "PLAINTEXT"
+}
+
+// parseRsp parses a CAVP response file. Comments are skipped, [ENCRYPT] and [DECRYPT] headers set
+// the direction of the records that follow, and records are separated by blank lines.
+func parseRsp(r io.Reader) ([]cavpVector, error) {
+	var vectors []cavpVector
+	var current map[string]string
+	encrypt := true
+	flush := func() {
+		if current != nil {
+			vectors = append(vectors, cavpVector{encrypt: encrypt, fields: current})
+			current = nil
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "["):
+			flush()
+			switch line {
+			case "[ENCRYPT]":
+				encrypt = true
+			case "[DECRYPT]":
+				encrypt = false
+			default:
+				return nil, fmt.Errorf("unknown section %q", line)
+			}
+		default:
+			parts := strings.SplitN(line, "=", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("malformed line %q", line)
+			}
+			if current == nil {
+				current = make(map[string]string)
+			}
+			current[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	flush()
+	return vectors, scanner.Err()
+}
+
+func TestParseRsp(t *testing.T) {
+	input := "# CAVS 11.1\r\n\r\n[ENCRYPT]\r\n\r\nCOUNT = 0\r\nKEY = 00\r\nPLAINTEXT = 01\r\nCIPHERTEXT = 02\r\n\r\n" +
+		"[DECRYPT]\r\n\r\nCOUNT = 0\r\nKEY = 03\r\nCIPHERTEXT = 04\r\nPLAINTEXT = 05\r\n"
+	vectors, err := parseRsp(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	if len(vectors) != 2 {
+		t.Fatalf("Want 2 vectors got %d", len(vectors))
+	}
+	if !vectors[0].encrypt || vectors[0].fields["CIPHERTEXT"] != "02" {
+		t.Errorf("Want encrypt vector with CIPHERTEXT 02 got %v", vectors[0])
+	}
+	if vectors[1].encrypt || vectors[1].fields["PLAINTEXT"] != "05" {
+		t.Errorf("Want decrypt vector with PLAINTEXT 05 got %v", vectors[1])
+	}
+
+	if _, err := parseRsp(strings.NewReader("[FOO]\n")); err == nil {
+		t.Errorf("Want err got nil")
+	}
+	if _, err := parseRsp(strings.NewReader("KEY\n")); err == nil {
+		t.Errorf("Want err got nil")
+	}
+}
+
+// runEngine runs an Ecb or Cbc engine for the vector and checks its output.
+func runEngine(t *testing.T, v cavpVector, cbc bool) {
+	t.Helper()
+	key := v.key(t)
+	input := v.bytes(t, v.input())
+	expected := v.bytes(t, v.output())
+
+	var engine io.Reader
+	if cbc {
+		engine = aes.Cbc(key, v.block(t, "IV"), v.encrypt, bytes.NewReader(input))
+	} else {
+		engine = aes.Ecb(key, v.encrypt, bytes.NewReader(input))
+	}
+	output := new(bytes.Buffer)
+	if _, err := io.Copy(output, engine); err != nil {
+		t.Errorf("COUNT %s: want nil got %v", v.fields["COUNT"], err)
+	}
+	if !bytes.Equal(output.Bytes(), expected) {
+		t.Errorf("COUNT %s (encrypt %v): want %x got %x", v.fields["COUNT"], v.encrypt, expected, output.Bytes())
+	}
+}
+
+// runRijndael runs a single-block ECB vector through Rijndael directly.
+func runRijndael(t *testing.T, v cavpVector) {
+	t.Helper()
+	expected := v.block(t, v.output())
+	output, err := aes.Rijndael(v.key(t), v.encrypt, v.block(t, v.input()))
+	if err != nil {
+		t.Fatalf("COUNT %s: want nil got %v", v.fields["COUNT"], err)
+	}
+	if *output != expected {
+		t.Errorf("COUNT %s (encrypt %v): want %x got %x", v.fields["COUNT"], v.encrypt, expected, output)
+	}
+}
+
+// monteCarlo runs the AESAVS Monte Carlo inner loop and returns the last two outputs. If step is not
+// nil it is called with the plaintext and ciphertext of each iteration.
+func monteCarlo(t *testing.T, key aes.Key, cbc, encrypt bool, iv, in [16]byte, step func(plaintext, ciphertext [16]byte)) (last, prior [16]byte) {
+	t.Helper()
+	expanded, err := aes.Expand(key)
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	var out, prevIn, prevOut [16]byte
+	for j := 0; j < 1000; j++ {
+		prior = out
+		if !cbc {
+			out = expanded.Crypt(encrypt, in)
+			prevIn, in = in, out
+		} else {
+			// The chaining value is the previous ciphertext: the previous output when encrypting,
+			// the previous input when decrypting.
+			chain, next := iv, iv
+			if j > 0 {
+				chain, next = prevOut, prevOut
+				if !encrypt {
+					chain = prevIn
+				}
+			}
+			if encrypt {
+				block := in
+				for i := range block {
+					block[i] ^= chain[i]
+				}
+				out = expanded.Crypt(true, block)
+			} else {
+				out = expanded.Crypt(false, in)
+				for i := range out {
+					out[i] ^= chain[i]
+				}
+			}
+			// The next input is the IV for the first iteration, then the output from two
+			// iterations ago.
+			prevIn, prevOut = in, out
+			in = next
+		}
+		if step != nil {
+			if encrypt {
+				step(prevIn, out)
+			} else {
+				step(out, prevIn)
+			}
+		}
+	}
+	return out, prior
+}
+
+// runMonteCarlo runs the AESAVS Monte Carlo test over the records of a file. Each record holds the
+// starting values for one iteration of the outer loop and the expected last output, and the starting
+// values of each record after the first in a section are checked against the outer loop too.
+func runMonteCarlo(t *testing.T, vectors []cavpVector, cbc bool) {
+	t.Helper()
+	var nextKey []byte
+	var nextIV, nextIn [16]byte
+	for i, v := range vectors {
+		count := v.fields["COUNT"]
+		keyBytes := v.bytes(t, "KEY")
+		var iv [16]byte
+		if cbc {
+			iv = v.block(t, "IV")
+		}
+		in := v.block(t, v.input())
+		if i > 0 && vectors[i-1].encrypt == v.encrypt {
+			if !bytes.Equal(keyBytes, nextKey) || iv != nextIV || in != nextIn {
+				t.Errorf("COUNT %s (encrypt %v): record does not follow from the last one", count, v.encrypt)
+			}
+		}
+
+		last, prior := monteCarlo(t, v.key(t), cbc, v.encrypt, iv, in, nil)
+		if expected := v.block(t, v.output()); last != expected {
+			t.Errorf("COUNT %s (encrypt %v): want %x got %x", count, v.encrypt, expected, last)
+		}
+
+		// The next key is the current one XORed with as many of the last output bytes as it has
+		tail := append(prior[:], last[:]...)
+		tail = tail[len(tail)-len(keyBytes):]
+		nextKey = make([]byte, len(keyBytes))
+		for j := range nextKey {
+			nextKey[j] = keyBytes[j] ^ tail[j]
+		}
+		if cbc {
+			nextIV, nextIn = last, prior
+		} else {
+			nextIn = last
+		}
+	}
+}
+
+// TestMonteCarloChaining checks the Monte Carlo inner loop against the Cbc engine: each iteration
+// is one block of a CBC message, so the plaintexts and ciphertexts seen in turn must agree with a
+// single pass of the engine over them, in both directions.
+func TestMonteCarloChaining(t *testing.T) {
+	key, err := aes.NewKey([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	iv := [16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	seed := [16]byte{0xff, 0xee, 0xdd, 0xcc, 0xbb, 0xaa, 0x99, 0x88}
+	for _, encrypt := range []bool{true, false} {
+		plaintext, ciphertext := new(bytes.Buffer), new(bytes.Buffer)
+		monteCarlo(t, key, true, encrypt, iv, seed, func(p, c [16]byte) {
+			plaintext.Write(p[:])
+			ciphertext.Write(c[:])
+		})
+
+		input, expected := plaintext, ciphertext
+		if !encrypt {
+			input, expected = ciphertext, plaintext
+		}
+		output := new(bytes.Buffer)
+		if _, err := io.Copy(output, aes.Cbc(key, iv, encrypt, bytes.NewReader(input.Bytes()))); err != nil {
+			t.Errorf("Encrypt %v: want nil got %v", encrypt, err)
+		}
+		if !bytes.Equal(output.Bytes(), expected.Bytes()) {
+			t.Errorf("Encrypt %v: inner loop does not chain like CBC", encrypt)
+		}
+	}
+}
+
+func TestCavp(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.rsp"))
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	if len(files) == 0 {
+		t.Fatalf("No .rsp files found in testdata")
+	}
+
+	for _, file := range files {
+		file := file
+		name := filepath.Base(file)
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			f, err := os.Open(file)
+			if err != nil {
+				t.Fatalf("Want nil got %v", err)
+			}
+			defer f.Close()
+			vectors, err := parseRsp(f)
+			if err != nil {
+				t.Fatalf("Want nil got %v", err)
+			}
+
+			cbc := strings.HasPrefix(name, "CBC")
+			if !cbc && !strings.HasPrefix(name, "ECB") {
+				t.Skipf("unsupported mode")
+			}
+			if strings.Contains(name, "MCT") {
+				runMonteCarlo(t, vectors, cbc)
+				return
+			}
+			for _, v := range vectors {
+				switch {
+				case strings.Contains(name, "MMT") || cbc:
+					runEngine(t, v, cbc)
+				default:
+					runEngine(t, v, false)
+					runRijndael(t, v)
+				}
+			}
+		})
+	}
+}
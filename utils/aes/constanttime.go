@@ -0,0 +1,107 @@
+package aes
+
+// The functions in this file implement the Rijndael round operations without table lookups or
+// branches on secret data, so that their timing does not depend on the key or the state. The
+// s-box is computed as the GF(2^8) inverse (x^254) followed by the affine transformation.
+
+// ctXtime multiplies b by x under GF(2^8) without branching on b.
+func ctXtime(b byte) byte {
+	return b<<1 ^ (0x1b & -(b >> 7))
+}
+
+// ctMul multiplies a and b under GF(2^8) in a fixed number of steps.
+func ctMul(a, b byte) byte {
+	var r byte
+	for i := 0; i < 8; i++ {
+		r ^= a & -(b & 1)
+		a = ctXtime(a)
+		b >>= 1
+	}
+	return r
+}
+
+// ctInverse returns the multiplicative inverse of x under GF(2^8), with 0 mapping to 0.
+func ctInverse(x byte) byte {
+	// x^254 = x^(2+4+8+16+32+64+128)
+	r := byte(1)
+	p := x
+	for i := 0; i < 7; i++ {
+		p = ctMul(p, p)
+		r = ctMul(r, p)
+	}
+	return r
+}
+
+func rotl8(b byte, n uint) byte {
+	return b<<n | b>>(8-n)
+}
+
+// ctSbox computes the AES s-box.
+func ctSbox(x byte) byte {
+	b := ctInverse(x)
+	return b ^ rotl8(b, 1) ^ rotl8(b, 2) ^ rotl8(b, 3) ^ rotl8(b, 4) ^ 0x63
+}
+
+// ctInvSbox computes the inverse AES s-box.
+func ctInvSbox(x byte) byte {
+	return ctInverse(rotl8(x, 1) ^ rotl8(x, 3) ^ rotl8(x, 6) ^ 0x05)
+}
+
+func ctSubBytes(state *[16]byte, encrypt bool) {
+	for i := range state {
+		if encrypt {
+			state[i] = ctSbox(state[i])
+		} else {
+			state[i] = ctInvSbox(state[i])
+		}
+	}
+}
+
+func ctMixColumns(state *[16]byte, encrypt bool) {
+	for c := 0; c < 16; c += 4 {
+		a0, a1, a2, a3 := state[c], state[c+1], state[c+2], state[c+3]
+		if encrypt {
+			state[c] = ctMul(2, a0) ^ ctMul(3, a1) ^ a2 ^ a3
+			state[c+1] = a0 ^ ctMul(2, a1) ^ ctMul(3, a2) ^ a3
+			state[c+2] = a0 ^ a1 ^ ctMul(2, a2) ^ ctMul(3, a3)
+			state[c+3] = ctMul(3, a0) ^ a1 ^ a2 ^ ctMul(2, a3)
+		} else {
+			state[c] = ctMul(14, a0) ^ ctMul(11, a1) ^ ctMul(13, a2) ^ ctMul(9, a3)
+			state[c+1] = ctMul(9, a0) ^ ctMul(14, a1) ^ ctMul(11, a2) ^ ctMul(13, a3)
+			state[c+2] = ctMul(13, a0) ^ ctMul(9, a1) ^ ctMul(14, a2) ^ ctMul(11, a3)
+			state[c+3] = ctMul(11, a0) ^ ctMul(13, a1) ^ ctMul(9, a2) ^ ctMul(14, a3)
+		}
+	}
+}
+
+// constantTimeEncrypt encrypts a single block with the given round keys. roundKeys must hold at
+// least two round keys.
+func constantTimeEncrypt(roundKeys [][16]byte, data [16]byte) [16]byte {
+	state := data
+	AddRoundKey(&state, &roundKeys[0])
+	for r := 1; r < len(roundKeys); r++ {
+		ctSubBytes(&state, true)
+		ShiftRows(&state, true)
+		if r != len(roundKeys)-1 {
+			ctMixColumns(&state, true)
+		}
+		AddRoundKey(&state, &roundKeys[r])
+	}
+	return state
+}
+
+// constantTimeDecrypt decrypts a single block with the given round keys, which are in encryption
+// order. roundKeys must hold at least two round keys.
+func constantTimeDecrypt(roundKeys [][16]byte, data [16]byte) [16]byte {
+	state := data
+	AddRoundKey(&state, &roundKeys[len(roundKeys)-1])
+	for r := len(roundKeys) - 2; r >= 0; r-- {
+		ShiftRows(&state, false)
+		ctSubBytes(&state, false)
+		AddRoundKey(&state, &roundKeys[r])
+		if r != 0 {
+			ctMixColumns(&state, false)
+		}
+	}
+	return state
+}
@@ -0,0 +1,32 @@
+package aes_test
+
+import (
+	"bytes"
+	"cryptopals/utils/aes"
+	"math/rand"
+	"testing"
+)
+
+func TestConstantTimeMatchesRijndael(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for _, size := range []int{16, 24, 32} {
+		for i := 0; i < 100; i++ {
+			key := randomKey(t, rng, size)
+			var data [16]byte
+			rng.Read(data[:])
+			for _, encrypt := range []bool{true, false} {
+				want, err := aes.Rijndael(key, encrypt, data)
+				if err != nil {
+					t.Fatalf("Want nil got %v", err)
+				}
+				got, err := aes.Rijndael(aes.WithImplementation(key, aes.CONSTANTTIME), encrypt, data)
+				if err != nil {
+					t.Fatalf("Want nil got %v", err)
+				}
+				if !bytes.Equal(want[:], got[:]) {
+					t.Errorf("Key size %d, encrypt %v, data %v: want %v got %v", size, encrypt, data, want, got)
+				}
+			}
+		}
+	}
+}
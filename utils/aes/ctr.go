@@ -0,0 +1,207 @@
+package aes
+
+import (
+	"cryptopals/utils/channels"
+	"fmt"
+	"io"
+)
+
+// CounterFormat represents a layout for the counter block used in CTR mode.
+type CounterFormat int
+
+const (
+	// CRYPTOPALS uses the first 8 bytes of the nonce as a fixed nonce, followed by a 64-bit
+	// little-endian block counter. The last 8 bytes of the nonce are the initial value of the
+	// counter, which is zero in the cryptopals challenges.
+	CRYPTOPALS CounterFormat = iota
+	// NIST treats the whole 16-byte nonce as the initial value of a 128-bit big-endian counter.
+	NIST
+)
+
+// checkCounterFormat returns an error if format is not a valid CounterFormat.
+func checkCounterFormat(format CounterFormat) error {
+	if format < CRYPTOPALS || format > NIST {
+		return fmt.Errorf("invalid counter format %d", format)
+	}
+	return nil
+}
+
+// counterBlock returns the counter block for the block at index n of a CTR keystream.
+// format MUST be a valid CounterFormat
+func counterBlock(nonce [16]byte, format CounterFormat, n uint64) [16]byte {
+	r := nonce
+	switch format {
+	case CRYPTOPALS:
+		// add n into the little-endian counter in the second half of the block
+		carry := n
+		for i := 8; i < 16 && carry != 0; i++ {
+			sum := uint64(r[i]) + (carry & 0xff)
+			r[i] = byte(sum)
+			carry = (carry >> 8) + (sum >> 8)
+		}
+	case NIST:
+		// add n into the big-endian counter that spans the whole block
+		carry := n
+		for i := 15; i >= 0 && carry != 0; i-- {
+			sum := uint64(r[i]) + (carry & 0xff)
+			r[i] = byte(sum)
+			carry = (carry >> 8) + (sum >> 8)
+		}
+	default:
+		panic("invalid counter format")
+	}
+	return r
+}
+
+// Ctr creates an AES-CTR encryption/decryption engine using the supplied key, nonce and counter
+// format. Since CTR is a stream mode, encryption and decryption are the same operation and the
+// data does not need to be a multiple of 16 bytes.
+func Ctr(key Key, nonce [16]byte, format CounterFormat, r io.Reader) *channels.Reader {
+	o := make(chan byte)
+	e := make(chan error)
+
+	// Create a goroutine which XORs the data with the keystream as it becomes available.
+	go func() {
+		defer close(e)
+		defer close(o)
+		if err := checkCounterFormat(format); err != nil {
+			e <- err
+			return
+		}
+		expanded, err := Expand(key)
+		if err != nil {
+			e <- err
+			return
+		}
+		var buf [16]byte
+		var keystream *[16]byte
+		block := uint64(0)
+		idx := 16
+		for {
+			count, err := r.Read(buf[:])
+			for _, b := range buf[:count] {
+				if idx == 16 {
+					var ksErr error
+					keystream, ksErr = Rijndael(expanded, true, counterBlock(nonce, format, block))
+					if ksErr != nil {
+						e <- ksErr
+						return
+					}
+					block++
+					idx = 0
+				}
+				o <- b ^ keystream[idx]
+				idx++
+			}
+			if err != nil {
+				e <- err
+				return
+			}
+		}
+	}()
+
+	return channels.NewReader(o, e)
+}
+
+// CtrBuffer holds AES-CTR ciphertext and provides random access to the plaintext behind it by
+// jumping the counter straight to the block of interest.
+type CtrBuffer struct {
+	key        Key
+	nonce      [16]byte
+	format     CounterFormat
+	ciphertext []byte
+}
+
+// NewCtrBuffer creates a CtrBuffer over the given ciphertext. The CtrBuffer takes ownership of
+// ciphertext.
+func NewCtrBuffer(key Key, nonce [16]byte, format CounterFormat, ciphertext []byte) (*CtrBuffer, error) {
+	if err := checkCounterFormat(format); err != nil {
+		return nil, err
+	}
+	return &CtrBuffer{
+		key:        key,
+		nonce:      nonce,
+		format:     format,
+		ciphertext: ciphertext,
+	}, nil
+}
+
+// Bytes returns the current ciphertext.
+func (c *CtrBuffer) Bytes() []byte {
+	return c.ciphertext
+}
+
+// xorKeystreamAt XORs dst with the keystream starting at byte offset off. Only the blocks covering
+// dst are generated.
+func (c *CtrBuffer) xorKeystreamAt(dst []byte, off int64) error {
+	expanded, err := Expand(c.key)
+	if err != nil {
+		return err
+	}
+	block := uint64(off / 16)
+	idx := int(off % 16)
+	for i := 0; i < len(dst); {
+		keystream := expanded.Encrypt(counterBlock(c.nonce, c.format, block))
+		for ; idx < 16 && i < len(dst); idx++ {
+			dst[i] ^= keystream[idx]
+			i++
+		}
+		block++
+		idx = 0
+	}
+	return nil
+}
+
+// ReadAt decrypts len(p) bytes of plaintext starting at byte offset off.
+func (c *CtrBuffer) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset %d", off)
+	}
+	if off >= int64(len(c.ciphertext)) {
+		return 0, io.EOF
+	}
+	n := copy(p, c.ciphertext[off:])
+	if err := c.xorKeystreamAt(p[:n], off); err != nil {
+		return 0, err
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// WriteAt encrypts p as plaintext at byte offset off, overwriting the ciphertext there. Writing
+// past the end grows the ciphertext, and any gap is filled with encrypted zeros.
+func (c *CtrBuffer) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset %d", off)
+	}
+	if end := off + int64(len(p)); end > int64(len(c.ciphertext)) {
+		oldLen := int64(len(c.ciphertext))
+		c.ciphertext = append(c.ciphertext, make([]byte, end-oldLen)...)
+		if off > oldLen {
+			if err := c.xorKeystreamAt(c.ciphertext[oldLen:off], oldLen); err != nil {
+				return 0, err
+			}
+		}
+	}
+	dst := c.ciphertext[off : off+int64(len(p))]
+	copy(dst, p)
+	if err := c.xorKeystreamAt(dst, off); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Edit returns a copy of ciphertext with the plaintext at offset replaced by newText. Only the
+// blocks touched by newText are re-encrypted.
+func (c *CtrBuffer) Edit(ciphertext []byte, offset int, newText []byte) ([]byte, error) {
+	edited, err := NewCtrBuffer(c.key, c.nonce, c.format, append([]byte{}, ciphertext...))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := edited.WriteAt(newText, int64(offset)); err != nil {
+		return nil, err
+	}
+	return edited.Bytes(), nil
+}
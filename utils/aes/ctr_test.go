@@ -0,0 +1,157 @@
+package aes_test
+
+import (
+	"bytes"
+	"cryptopals/utils/aes"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestCtr(t *testing.T) {
+	cases := []struct {
+		name     string
+		key      aes.Key
+		nonce    [16]byte
+		format   aes.CounterFormat
+		input    []byte
+		expected []byte
+	}{
+		{
+			// NIST SP 800-38A F.5.1
+			name:   "NIST",
+			key:    aes.NewKey128([16]byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c}),
+			nonce:  [16]byte{0xf0, 0xf1, 0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7, 0xf8, 0xf9, 0xfa, 0xfb, 0xfc, 0xfd, 0xfe, 0xff},
+			format: aes.NIST,
+			input: []byte{0x6b, 0xc1, 0xbe, 0xe2, 0x2e, 0x40, 0x9f, 0x96, 0xe9, 0x3d, 0x7e, 0x11, 0x73, 0x93, 0x17, 0x2a,
+				0xae, 0x2d, 0x8a, 0x57, 0x1e, 0x03, 0xac, 0x9c, 0x9e, 0xb7, 0x6f, 0xac, 0x45, 0xaf, 0x8e, 0x51,
+				0x30, 0xc8, 0x1c, 0x46, 0xa3, 0x5c, 0xe4, 0x11, 0xe5, 0xfb, 0xc1, 0x19, 0x1a, 0x0a, 0x52, 0xef,
+				0xf6, 0x9f, 0x24, 0x45, 0xdf, 0x4f, 0x9b, 0x17, 0xad, 0x2b, 0x41, 0x7b, 0xe6, 0x6c, 0x37, 0x10},
+			expected: []byte{0x87, 0x4d, 0x61, 0x91, 0xb6, 0x20, 0xe3, 0x26, 0x1b, 0xef, 0x68, 0x64, 0x99, 0x0d, 0xb6, 0xce,
+				0x98, 0x06, 0xf6, 0x6b, 0x79, 0x70, 0xfd, 0xff, 0x86, 0x17, 0x18, 0x7b, 0xb9, 0xff, 0xfd, 0xff,
+				0x5a, 0xe4, 0xdf, 0x3e, 0xdb, 0xd5, 0xd3, 0x5e, 0x5b, 0x4f, 0x09, 0x02, 0x0d, 0xb0, 0x3e, 0xab,
+				0x1e, 0x03, 0x1d, 0xda, 0x2f, 0xbe, 0x03, 0xd1, 0x79, 0x21, 0x70, 0xa0, 0xf3, 0x00, 0x9c, 0xee},
+		},
+		{
+			// Cryptopals set 3 challenge 18
+			name:   "Cryptopals",
+			key:    aes.NewKey128([16]byte{'Y', 'E', 'L', 'L', 'O', 'W', ' ', 'S', 'U', 'B', 'M', 'A', 'R', 'I', 'N', 'E'}),
+			format: aes.CRYPTOPALS,
+			input: []byte{0x2f, 0xbe, 0xe7, 0x6b, 0xf9, 0xeb, 0x16, 0xc2, 0xaf, 0xca, 0x77, 0x7a, 0x1f, 0x33, 0xa8, 0x1b,
+				0xb1, 0x87, 0x4c, 0xb5, 0xec, 0x4d, 0x5b, 0xbd, 0xaa, 0xf6, 0x3f, 0xda, 0xcc, 0x8b, 0x5f, 0x38,
+				0x4f, 0xc1, 0xec, 0xb2, 0x31, 0x32, 0x54, 0x2e, 0xef, 0xfa, 0xfe, 0x45, 0xd7, 0xd0, 0xa4, 0xaf,
+				0xa0, 0xe2, 0xd2, 0x15},
+			expected: []byte("Yo, VIP Let's kick it Ice, Ice, baby Ice, Ice, baby "),
+		},
+	}
+
+	for _, c := range cases {
+		// loop variable c will be captured by reference, so we shadow it with a new variable also
+		// called c
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			// feed the engine one byte at a time to exercise partial blocks
+			ctr := aes.Ctr(c.key, c.nonce, c.format, iotest.OneByteReader(bytes.NewReader(c.input)))
+			output := new(bytes.Buffer)
+			_, err := io.Copy(output, ctr)
+			if err != nil {
+				t.Errorf("Want nil got %v", err)
+			}
+			if !bytes.Equal(output.Bytes(), c.expected) {
+				t.Errorf("Want %v got %v", c.expected, output.Bytes())
+			}
+
+			// Now do it in reverse
+			ctr = aes.Ctr(c.key, c.nonce, c.format, bytes.NewReader(c.expected))
+			output = new(bytes.Buffer)
+			_, err = io.Copy(output, ctr)
+			if err != nil {
+				t.Errorf("Want nil got %v", err)
+			}
+			if !bytes.Equal(output.Bytes(), c.input) {
+				t.Errorf("Want %v got %v", c.input, output.Bytes())
+			}
+		})
+	}
+}
+
+func ctrEncrypt(t *testing.T, key aes.Key, nonce [16]byte, plaintext []byte) []byte {
+	t.Helper()
+	output := new(bytes.Buffer)
+	if _, err := io.Copy(output, aes.Ctr(key, nonce, aes.NIST, bytes.NewReader(plaintext))); err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	return output.Bytes()
+}
+
+func TestCtrBuffer(t *testing.T) {
+	key := aes.NewKey128([16]byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c})
+	nonce := [16]byte{0xf0, 0xf1, 0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7, 0xf8, 0xf9, 0xfa, 0xfb, 0xfc, 0xfd, 0xfe, 0xff}
+	plaintext := []byte("The quick brown fox jumps over the lazy dog, and then does it again.")
+	ciphertext := ctrEncrypt(t, key, nonce, plaintext)
+
+	buf, err := aes.NewCtrBuffer(key, nonce, aes.NIST, append([]byte{}, ciphertext...))
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+
+	// Read from the middle of a block across a block boundary
+	p := make([]byte, 20)
+	n, err := buf.ReadAt(p, 10)
+	if err != nil {
+		t.Errorf("Want nil got %v", err)
+	}
+	if !bytes.Equal(p[:n], plaintext[10:30]) {
+		t.Errorf("Want %q got %q", plaintext[10:30], p[:n])
+	}
+
+	// Read off the end
+	p = make([]byte, 20)
+	n, err = buf.ReadAt(p, int64(len(plaintext)-5))
+	if err != io.EOF {
+		t.Errorf("Want EOF got %v", err)
+	}
+	if !bytes.Equal(p[:n], plaintext[len(plaintext)-5:]) {
+		t.Errorf("Want %q got %q", plaintext[len(plaintext)-5:], p[:n])
+	}
+
+	// Edit in the middle and compare against encrypting the edited plaintext from scratch
+	edited, err := buf.Edit(ciphertext, 16, []byte("QUICK BROWN"))
+	if err != nil {
+		t.Errorf("Want nil got %v", err)
+	}
+	editedPlaintext := append([]byte{}, plaintext...)
+	copy(editedPlaintext[16:], []byte("QUICK BROWN"))
+	expected := ctrEncrypt(t, key, nonce, editedPlaintext)
+	if !bytes.Equal(edited, expected) {
+		t.Errorf("Want %v got %v", expected, edited)
+	}
+	if !bytes.Equal(buf.Bytes(), ciphertext) {
+		t.Errorf("Edit modified the buffer")
+	}
+
+	// Write past the end, leaving a gap of zeros
+	if _, err := buf.WriteAt([]byte("!!"), int64(len(plaintext)+3)); err != nil {
+		t.Errorf("Want nil got %v", err)
+	}
+	grownPlaintext := append(append([]byte{}, plaintext...), 0, 0, 0, '!', '!')
+	expected = ctrEncrypt(t, key, nonce, grownPlaintext)
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Errorf("Want %v got %v", expected, buf.Bytes())
+	}
+}
+
+func TestCtrInvalidFormat(t *testing.T) {
+	key := aes.NewKey128([16]byte{})
+	format := aes.CounterFormat(9)
+	if _, err := io.Copy(io.Discard, aes.Ctr(key, [16]byte{}, format, bytes.NewReader(make([]byte, 32)))); err == nil {
+		t.Errorf("Ctr: want err got nil")
+	}
+	if _, err := aes.NewCtrBuffer(key, [16]byte{}, format, nil); err == nil {
+		t.Errorf("NewCtrBuffer: want err got nil")
+	}
+	if _, err := aes.NewCtrStream(key, [16]byte{}, format); err == nil {
+		t.Errorf("NewCtrStream: want err got nil")
+	}
+}
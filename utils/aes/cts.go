@@ -0,0 +1,139 @@
+package aes
+
+import (
+	"cryptopals/utils/channels"
+	"fmt"
+	"io"
+)
+
+// CtsMode selects how CBC ciphertext stealing orders the last two blocks of ciphertext, following
+// the addendum to NIST SP 800-38A.
+type CtsMode int
+
+const (
+	// CS1 keeps the partial block before the last full block, so data which is a multiple of 16
+	// bytes encrypts exactly as CBC.
+	CS1 CtsMode = iota
+	// CS2 swaps the last two blocks only when the final block is partial.
+	CS2
+	// CS3 always swaps the last two blocks. This is the variant used by Kerberos (RFC 3962).
+	CS3
+)
+
+// swapped returns whether the last two blocks of ciphertext are swapped when the final block holds
+// d bytes.
+func (m CtsMode) swapped(d int) bool {
+	switch m {
+	case CS1:
+		return false
+	case CS2:
+		return d != 16
+	default:
+		return true
+	}
+}
+
+// CbcCts creates an AES-CBC encryption/decryption engine (depends on the value of `encrypt`) which
+// uses ciphertext stealing for the last two blocks, so that the ciphertext is exactly as long as
+// the plaintext. The data must be at least 16 bytes long.
+func CbcCts(key Key, iv [16]byte, mode CtsMode, encrypt bool, r io.Reader) *channels.Reader {
+	o := make(chan byte)
+	e := make(chan error)
+
+	// Create a goroutine which encrypts/decrypts data block by block. The last two blocks are held
+	// back until the end of the data.
+	go func() {
+		defer close(e)
+		defer close(o)
+		if mode < CS1 || mode > CS3 {
+			e <- fmt.Errorf("invalid ciphertext stealing mode %d", mode)
+			return
+		}
+		expanded, err := Expand(key)
+		if err != nil {
+			e <- err
+			return
+		}
+		chain := cbcChain{key: expanded, prev: iv}
+
+		send := func(b []byte) {
+			for _, c := range b {
+				o <- c
+			}
+		}
+
+		var pending []byte
+		buf := make([]byte, 16)
+		for {
+			count, err := r.Read(buf)
+			pending = append(pending, buf[:count]...)
+			for len(pending) > 32 {
+				var block [16]byte
+				copy(block[:], pending)
+				out := chain.crypt(encrypt, block)
+				send(out[:])
+				pending = pending[16:]
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				e <- err
+				return
+			}
+		}
+
+		switch {
+		case len(pending) < 16:
+			e <- fmt.Errorf("CBC ciphertext stealing requires at least 16 bytes of data")
+			return
+		case len(pending) == 16:
+			var block [16]byte
+			copy(block[:], pending)
+			out := chain.crypt(encrypt, block)
+			send(out[:])
+		case encrypt:
+			// Encrypt the zero padded final block as CBC, then drop the tail of the second last
+			// block of ciphertext.
+			d := len(pending) - 16
+			var p1, p2 [16]byte
+			copy(p1[:], pending)
+			copy(p2[:], pending[16:])
+			c1 := chain.crypt(true, p1)
+			c2 := chain.crypt(true, p2)
+			if mode.swapped(d) {
+				send(c2[:])
+				send(c1[:d])
+			} else {
+				send(c1[:d])
+				send(c2[:])
+			}
+		default:
+			// Decrypting the final full block recovers the dropped tail of the second last block
+			// of ciphertext, XORed with the zero padding.
+			d := len(pending) - 16
+			var stolen []byte
+			var c2 [16]byte
+			if mode.swapped(d) {
+				copy(c2[:], pending)
+				stolen = pending[16:]
+			} else {
+				stolen = pending[:d]
+				copy(c2[:], pending[d:])
+			}
+			z := expanded.Decrypt(c2)
+			var c1 [16]byte
+			copy(c1[:], stolen)
+			copy(c1[d:], z[d:])
+			p1 := chain.crypt(false, c1)
+			send(p1[:])
+			for i := 0; i < d; i++ {
+				z[i] ^= c1[i]
+			}
+			send(z[:d])
+		}
+		e <- io.EOF
+	}()
+
+	return channels.NewReader(o, e)
+}
@@ -0,0 +1,109 @@
+package aes_test
+
+import (
+	"bytes"
+	"cryptopals/utils/aes"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestCbcCts(t *testing.T) {
+	// RFC 3962, Appendix B. Kerberos uses CS3 with a zero IV.
+	key := aes.NewKey128([16]byte{'c', 'h', 'i', 'c', 'k', 'e', 'n', ' ', 't', 'e', 'r', 'i', 'y', 'a', 'k', 'i'})
+	message := []byte("I would like the General Gau's Chicken, please, and wonton soup.")
+	cases := []struct {
+		length     int
+		ciphertext string
+	}{
+		{17, "c6353568f2bf8cb4d8a580362da7ff7f97"},
+		{31, "fc00783e0efdb2c1d445d4c8eff7ed2297687268d6ecccc0c07b25e25ecfe5"},
+		{32, "39312523a78662d5be7fcbcc98ebf5a897687268d6ecccc0c07b25e25ecfe584"},
+		{47, "97687268d6ecccc0c07b25e25ecfe584b3fffd940c16a18c1b5549d2f838029e39312523a78662d5be7fcbcc98ebf5"},
+		{48, "97687268d6ecccc0c07b25e25ecfe5849dad8bbb96c4cdc03bc103e1a194bbd839312523a78662d5be7fcbcc98ebf5a8"},
+		{64, "97687268d6ecccc0c07b25e25ecfe58439312523a78662d5be7fcbcc98ebf5a84807efe836ee89a526730dbc2f7bc8409dad8bbb96c4cdc03bc103e1a194bbd8"},
+	}
+
+	for _, c := range cases {
+		plaintext := message[:c.length]
+		expected := unhex(t, c.ciphertext)
+
+		encrypted := new(bytes.Buffer)
+		if _, err := io.Copy(encrypted, aes.CbcCts(key, [16]byte{}, aes.CS3, true, iotest.OneByteReader(bytes.NewReader(plaintext)))); err != nil {
+			t.Errorf("Length %d: want nil got %v", c.length, err)
+		}
+		if !bytes.Equal(encrypted.Bytes(), expected) {
+			t.Errorf("Length %d: want %x got %x", c.length, expected, encrypted.Bytes())
+		}
+
+		decrypted := new(bytes.Buffer)
+		if _, err := io.Copy(decrypted, aes.CbcCts(key, [16]byte{}, aes.CS3, false, iotest.OneByteReader(bytes.NewReader(expected)))); err != nil {
+			t.Errorf("Length %d: want nil got %v", c.length, err)
+		}
+		if !bytes.Equal(decrypted.Bytes(), plaintext) {
+			t.Errorf("Length %d: want %q got %q", c.length, plaintext, decrypted.Bytes())
+		}
+	}
+}
+
+func TestCbcCtsModes(t *testing.T) {
+	ctsCrypt := func(mode aes.CtsMode, encrypt bool, data []byte) []byte {
+		t.Helper()
+		output := new(bytes.Buffer)
+		if _, err := io.Copy(output, aes.CbcCts(sp80038aKey, sp80038aIv, mode, encrypt, bytes.NewReader(data))); err != nil {
+			t.Fatalf("Want nil got %v", err)
+		}
+		return output.Bytes()
+	}
+
+	for n := 16; n <= len(sp80038aPt); n++ {
+		plaintext := sp80038aPt[:n]
+		cs1 := ctsCrypt(aes.CS1, true, plaintext)
+		cs2 := ctsCrypt(aes.CS2, true, plaintext)
+		cs3 := ctsCrypt(aes.CS3, true, plaintext)
+		if len(cs1) != n || len(cs2) != n || len(cs3) != n {
+			t.Fatalf("Length %d: got ciphertext lengths %d, %d, %d", n, len(cs1), len(cs2), len(cs3))
+		}
+
+		// CS3 is CS1 with the stolen partial block moved to the end
+		d := n % 16
+		if d == 0 {
+			d = 16
+		}
+		if n > 16 {
+			reordered := append([]byte{}, cs1[:n-16-d]...)
+			reordered = append(reordered, cs1[n-16:]...)
+			reordered = append(reordered, cs1[n-16-d:n-16]...)
+			if !bytes.Equal(cs3, reordered) {
+				t.Errorf("Length %d: CS3 want %x got %x", n, reordered, cs3)
+			}
+		}
+		if n%16 == 0 {
+			// CS1 and CS2 are plain CBC for whole blocks
+			cbc := new(bytes.Buffer)
+			if _, err := io.Copy(cbc, aes.Cbc(sp80038aKey, sp80038aIv, true, bytes.NewReader(plaintext))); err != nil {
+				t.Fatalf("Want nil got %v", err)
+			}
+			if !bytes.Equal(cs1, cbc.Bytes()) || !bytes.Equal(cs2, cbc.Bytes()) {
+				t.Errorf("Length %d: want %x got %x and %x", n, cbc.Bytes(), cs1, cs2)
+			}
+		} else if !bytes.Equal(cs2, cs3) {
+			t.Errorf("Length %d: CS2 want %x got %x", n, cs3, cs2)
+		}
+
+		for mode, ciphertext := range map[aes.CtsMode][]byte{aes.CS1: cs1, aes.CS2: cs2, aes.CS3: cs3} {
+			if decrypted := ctsCrypt(mode, false, ciphertext); !bytes.Equal(decrypted, plaintext) {
+				t.Errorf("Length %d mode %d: want %x got %x", n, mode, plaintext, decrypted)
+			}
+		}
+	}
+}
+
+func TestCbcCtsShort(t *testing.T) {
+	for _, n := range []int{0, 1, 15} {
+		_, err := io.Copy(io.Discard, aes.CbcCts(sp80038aKey, sp80038aIv, aes.CS1, true, bytes.NewReader(sp80038aPt[:n])))
+		if err == nil {
+			t.Errorf("Length %d: want an error got nil", n)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package aes
+
+import "fmt"
+
+// ExpandedKey is an AES key whose encryption and decryption round keys have been computed up
+// front, so that block operations do not need to run the key schedule.
+type ExpandedKey struct {
+	impl      Implementation
+	roundKeys [][16]byte
+	encWords  []uint32
+	decWords  []uint32
+}
+
+// Expand computes the round keys for key once. If key is already an ExpandedKey, it is returned
+// as-is. The implementation chosen with WithImplementation is carried over.
+func Expand(key Key) (*ExpandedKey, error) {
+	if k, ok := key.(*ExpandedKey); ok {
+		return k, nil
+	}
+
+	impl := TABLE
+	if k, ok := key.(implementationKey); ok {
+		impl = k.impl
+		key = k.Key
+	}
+	switch impl {
+	case TABLE, REFERENCE, CONSTANTTIME:
+	default:
+		return nil, fmt.Errorf("invalid implementation %d", impl)
+	}
+
+	if k, ok := key.(*ExpandedKey); ok {
+		// Re-use the round keys with a different implementation
+		r := *k
+		r.impl = impl
+		return &r, nil
+	}
+
+	var roundKeys [][16]byte
+	for roundKey := range key.KeySchedule() {
+		roundKeys = append(roundKeys, roundKey)
+	}
+	if len(roundKeys) < 2 {
+		return nil, fmt.Errorf("key schedule too short")
+	}
+
+	encWords := roundKeyWords(roundKeys)
+	return &ExpandedKey{
+		impl:      impl,
+		roundKeys: roundKeys,
+		encWords:  encWords,
+		decWords:  decryptionKeyWords(encWords),
+	}, nil
+}
+
+// reduced returns a copy of the key which only runs the given number of rounds.
+func (k *ExpandedKey) reduced(rounds int) (*ExpandedKey, error) {
+	if rounds < 1 || rounds >= len(k.roundKeys) {
+		return nil, fmt.Errorf("invalid number of rounds %d", rounds)
+	}
+	encWords := k.encWords[:4*(rounds+1)]
+	return &ExpandedKey{
+		impl:      k.impl,
+		roundKeys: k.roundKeys[:rounds+1],
+		encWords:  encWords,
+		decWords:  decryptionKeyWords(encWords),
+	}, nil
+}
+
+// KeySchedule returns the precomputed key schedule, so that an ExpandedKey can be used anywhere a
+// Key is expected.
+func (k *ExpandedKey) KeySchedule() chan [16]byte {
+	s := make(chan [16]byte)
+	go func() {
+		for _, roundKey := range k.roundKeys {
+			s <- roundKey
+		}
+		close(s)
+	}()
+
+	return s
+}
+
+// Crypt performs a raw AES operation on a single block of data (depends on the value of
+// `encrypt`).
+func (k *ExpandedKey) Crypt(encrypt bool, data [16]byte) [16]byte {
+	switch k.impl {
+	case REFERENCE:
+		return rijndaelReference(k.roundKeys, encrypt, data, nil)
+	case CONSTANTTIME:
+		if encrypt {
+			return constantTimeEncrypt(k.roundKeys, data)
+		}
+		return constantTimeDecrypt(k.roundKeys, data)
+	default:
+		if encrypt {
+			return tableEncrypt(k.encWords, data)
+		}
+		return tableDecrypt(k.decWords, data)
+	}
+}
+
+// Encrypt encrypts a single block of data.
+func (k *ExpandedKey) Encrypt(data [16]byte) [16]byte {
+	return k.Crypt(true, data)
+}
+
+// Decrypt decrypts a single block of data.
+func (k *ExpandedKey) Decrypt(data [16]byte) [16]byte {
+	return k.Crypt(false, data)
+}
@@ -0,0 +1,71 @@
+package aes_test
+
+import (
+	"bytes"
+	"cryptopals/utils/aes"
+	"testing"
+)
+
+func TestExpandedKey(t *testing.T) {
+	key := aes.NewKey128([16]byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c})
+	data := [16]byte{0x6b, 0xc1, 0xbe, 0xe2, 0x2e, 0x40, 0x9f, 0x96, 0xe9, 0x3d, 0x7e, 0x11, 0x73, 0x93, 0x17, 0x2a}
+	expected := [16]byte{0x3a, 0xd7, 0x7b, 0xb4, 0x0d, 0x7a, 0x36, 0x60, 0xa8, 0x9e, 0xca, 0xf3, 0x24, 0x66, 0xef, 0x97}
+
+	expanded, err := aes.Expand(key)
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+
+	// The channel API still produces the same key schedule
+	original := key.KeySchedule()
+	for roundKey := range expanded.KeySchedule() {
+		want := <-original
+		if !bytes.Equal(roundKey[:], want[:]) {
+			t.Errorf("Want %v got %v", want, roundKey)
+		}
+	}
+	if _, ok := <-original; ok {
+		t.Errorf("Expanded key schedule too short")
+	}
+
+	for _, impl := range []aes.Implementation{aes.TABLE, aes.REFERENCE, aes.CONSTANTTIME} {
+		withImpl, err := aes.Expand(aes.WithImplementation(expanded, impl))
+		if err != nil {
+			t.Fatalf("Want nil got %v", err)
+		}
+		result := withImpl.Encrypt(data)
+		if !bytes.Equal(result[:], expected[:]) {
+			t.Errorf("Implementation %d: want %v got %v", impl, expected, result)
+		}
+		result = withImpl.Decrypt(result)
+		if !bytes.Equal(result[:], data[:]) {
+			t.Errorf("Implementation %d: want %v got %v", impl, data, result)
+		}
+	}
+
+	again, err := aes.Expand(expanded)
+	if err != nil {
+		t.Errorf("Want nil got %v", err)
+	}
+	if again != expanded {
+		t.Errorf("Expanding an expanded key should return it unchanged")
+	}
+
+	if _, err := aes.Expand(aes.WithImplementation(key, aes.Implementation(42))); err == nil {
+		t.Errorf("Want err got nil")
+	}
+}
+
+func BenchmarkRijndaelExpanded(b *testing.B) {
+	key, err := aes.Expand(aes.NewKey128([16]byte{}))
+	if err != nil {
+		b.Fatalf("Want nil got %v", err)
+	}
+	var data [16]byte
+	b.SetBytes(16)
+	for n := 0; n < b.N; n++ {
+		if _, err := aes.Rijndael(key, true, data); err != nil {
+			b.Fatalf("Want nil got %v", err)
+		}
+	}
+}
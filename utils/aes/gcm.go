@@ -0,0 +1,128 @@
+package aes
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+)
+
+// Gcm is an AES-GCM authenticated encryption engine built on Rijndael and Gf128.
+type Gcm struct {
+	key     *ExpandedKey
+	h       Gf128
+	tagSize int
+}
+
+// NewGcm creates an AES-GCM engine using the supplied key, producing tags of tagSize bytes.
+// tagSize must be between 4 and 16.
+func NewGcm(key Key, tagSize int) (*Gcm, error) {
+	if tagSize < 4 || tagSize > 16 {
+		return nil, fmt.Errorf("invalid GCM tag size %d", tagSize)
+	}
+	expanded, err := Expand(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Gcm{
+		key:     expanded,
+		h:       Gf128(expanded.Encrypt([16]byte{})),
+		tagSize: tagSize,
+	}, nil
+}
+
+// H returns the GHASH key, i.e. the encryption of the all-zero block.
+func (g *Gcm) H() Gf128 {
+	return g.h
+}
+
+// TagSize returns the length in bytes of the tags produced by the engine.
+func (g *Gcm) TagSize() int {
+	return g.tagSize
+}
+
+// Ghash computes GHASH over the additional data and ciphertext with the given hash key, including
+// the final block of bit lengths.
+func Ghash(h Gf128, aad, ciphertext []byte) Gf128 {
+	var y Gf128
+	for _, data := range [][]byte{aad, ciphertext} {
+		for i := 0; i < len(data); i += 16 {
+			var block Gf128
+			copy(block[:], data[i:])
+			y = y.Add(block).Mul(h)
+		}
+	}
+	var lengths Gf128
+	binary.BigEndian.PutUint64(lengths[:8], uint64(len(aad))*8)
+	binary.BigEndian.PutUint64(lengths[8:], uint64(len(ciphertext))*8)
+	return y.Add(lengths).Mul(h)
+}
+
+// inc32 increments the last 32 bits of a counter block, modulo 2^32.
+func inc32(block [16]byte) [16]byte {
+	binary.BigEndian.PutUint32(block[12:], binary.BigEndian.Uint32(block[12:])+1)
+	return block
+}
+
+// j0 derives the pre-counter block from the nonce.
+func (g *Gcm) j0(nonce []byte) [16]byte {
+	var j [16]byte
+	if len(nonce) == 12 {
+		copy(j[:], nonce)
+		j[15] = 1
+		return j
+	}
+	return Ghash(g.h, nil, nonce)
+}
+
+// gctr XORs data in place with the keystream starting at counter block icb.
+func (g *Gcm) gctr(icb [16]byte, data []byte) {
+	cb := icb
+	for i := 0; i < len(data); i += 16 {
+		keystream := g.key.Encrypt(cb)
+		for j := 0; j < 16 && i+j < len(data); j++ {
+			data[i+j] ^= keystream[j]
+		}
+		cb = inc32(cb)
+	}
+}
+
+// tag computes the authentication tag for the given pre-counter block, additional data and
+// ciphertext.
+func (g *Gcm) tag(j0 [16]byte, aad, ciphertext []byte) []byte {
+	s := Ghash(g.h, aad, ciphertext)
+	g.gctr(j0, s[:])
+	return s[:g.tagSize]
+}
+
+// Seal encrypts and authenticates plaintext and authenticates aad, returning the ciphertext with
+// the tag appended.
+func (g *Gcm) Seal(nonce, plaintext, aad []byte) ([]byte, error) {
+	if len(nonce) == 0 {
+		return nil, fmt.Errorf("GCM nonce must not be empty")
+	}
+	j0 := g.j0(nonce)
+	out := make([]byte, len(plaintext), len(plaintext)+g.tagSize)
+	copy(out, plaintext)
+	g.gctr(inc32(j0), out)
+	return append(out, g.tag(j0, aad, out)...), nil
+}
+
+// Open authenticates and decrypts ciphertext (with the tag appended) and authenticates aad,
+// returning the plaintext.
+func (g *Gcm) Open(nonce, ciphertext, aad []byte) ([]byte, error) {
+	if len(nonce) == 0 {
+		return nil, fmt.Errorf("GCM nonce must not be empty")
+	}
+	if len(ciphertext) < g.tagSize {
+		return nil, fmt.Errorf("GCM ciphertext shorter than tag")
+	}
+	body := ciphertext[:len(ciphertext)-g.tagSize]
+	j0 := g.j0(nonce)
+	if subtle.ConstantTimeCompare(g.tag(j0, aad, body), ciphertext[len(body):]) != 1 {
+		return nil, fmt.Errorf("GCM message authentication failed")
+	}
+	out := make([]byte, len(body))
+	copy(out, body)
+	g.gctr(inc32(j0), out)
+	return out, nil
+}
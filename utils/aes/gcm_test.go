@@ -0,0 +1,141 @@
+package aes_test
+
+import (
+	"bytes"
+	"cryptopals/utils/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("bad test vector %q: %v", s, err)
+	}
+	return b
+}
+
+func TestGcm(t *testing.T) {
+	// Test cases from "The Galois/Counter Mode of Operation (GCM)", McGrew and Viega
+	cases := []struct {
+		name       string
+		key        string
+		nonce      string
+		plaintext  string
+		aad        string
+		ciphertext string
+		tag        string
+	}{
+		{
+			name:  "TestCase1",
+			key:   "00000000000000000000000000000000",
+			nonce: "000000000000000000000000",
+			tag:   "58e2fccefa7e3061367f1d57a4e7455a",
+		},
+		{
+			name:       "TestCase2",
+			key:        "00000000000000000000000000000000",
+			nonce:      "000000000000000000000000",
+			plaintext:  "00000000000000000000000000000000",
+			ciphertext: "0388dace60b6a392f328c2b971b2fe78",
+			tag:        "ab6e47d42cec13bdf53a67b21257bddf",
+		},
+		{
+			name:  "TestCase3",
+			key:   "feffe9928665731c6d6a8f9467308308",
+			nonce: "cafebabefacedbaddecaf888",
+			plaintext: "d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a72" +
+				"1c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b391aafd255",
+			ciphertext: "42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e" +
+				"21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091473f5985",
+			tag: "4d5c2af327cd64a62cf35abd2ba6fab4",
+		},
+		{
+			name:  "TestCase4",
+			key:   "feffe9928665731c6d6a8f9467308308",
+			nonce: "cafebabefacedbaddecaf888",
+			plaintext: "d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a72" +
+				"1c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b39",
+			aad: "feedfacedeadbeeffeedfacedeadbeefabaddad2",
+			ciphertext: "42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e" +
+				"21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091",
+			tag: "5bc94fbc3221a5db94fae95ae7121a47",
+		},
+		{
+			name:  "TestCase6",
+			key:   "feffe9928665731c6d6a8f9467308308",
+			nonce: "9313225df88406e555909c5aff5269aa6a7a9538534f7da1e4c303d2a318a728c3c0c95156809539fcf0e2429a6b525416aedbf5a0de6a57a637b39b",
+			plaintext: "d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a72" +
+				"1c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b39",
+			aad: "feedfacedeadbeeffeedfacedeadbeefabaddad2",
+			ciphertext: "8ce24998625615b603a033aca13fb894be9112a5c3a211a8ba262a3cca7e2ca7" +
+				"01e4a9a4fba43c90ccdcb281d48c7c6fd62875d2aca417034c34aee5",
+			tag: "619cc5aefffe0bfa462af43c1699d050",
+		},
+		{
+			name:       "TestCase14",
+			key:        "0000000000000000000000000000000000000000000000000000000000000000",
+			nonce:      "000000000000000000000000",
+			plaintext:  "00000000000000000000000000000000",
+			ciphertext: "cea7403d4d606b6e074ec5d3baf39d18",
+			tag:        "d0d1c8a799996bf0265b98b5d48ab919",
+		},
+	}
+
+	for _, c := range cases {
+		// loop variable c will be captured by reference, so we shadow it with a new variable also
+		// called c
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			key, err := aes.NewKey(unhex(t, c.key))
+			if err != nil {
+				t.Fatalf("Want nil got %v", err)
+			}
+			nonce := unhex(t, c.nonce)
+			plaintext := unhex(t, c.plaintext)
+			aad := unhex(t, c.aad)
+			expected := append(unhex(t, c.ciphertext), unhex(t, c.tag)...)
+
+			for _, tagSize := range []int{16, 12, 4} {
+				gcm, err := aes.NewGcm(key, tagSize)
+				if err != nil {
+					t.Fatalf("Want nil got %v", err)
+				}
+				want := expected[:len(expected)-16+tagSize]
+				sealed, err := gcm.Seal(nonce, plaintext, aad)
+				if err != nil {
+					t.Errorf("Want nil got %v", err)
+				}
+				if !bytes.Equal(sealed, want) {
+					t.Errorf("Tag size %d: want %x got %x", tagSize, want, sealed)
+				}
+
+				// Now do it in reverse
+				opened, err := gcm.Open(nonce, sealed, aad)
+				if err != nil {
+					t.Errorf("Want nil got %v", err)
+				}
+				if !bytes.Equal(opened, plaintext) {
+					t.Errorf("Want %x got %x", plaintext, opened)
+				}
+
+				// Flip a bit in the tag
+				sealed[len(sealed)-1] ^= 0x01
+				if _, err := gcm.Open(nonce, sealed, aad); err == nil {
+					t.Errorf("Want err got nil")
+				}
+			}
+		})
+	}
+}
+
+func TestNewGcmInvalidTagSize(t *testing.T) {
+	key := aes.NewKey128([16]byte{})
+	for _, tagSize := range []int{0, 3, 17} {
+		if _, err := aes.NewGcm(key, tagSize); err == nil {
+			t.Errorf("Tag size %d: want err got nil", tagSize)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package aes
+
+import "encoding/binary"
+
+// Gf128 is an element of GF(2^128) in the bit order used by GCM: the most significant bit of the
+// first byte is the coefficient of x^0, and the field is reduced by x^128 + x^7 + x^2 + x + 1.
+type Gf128 [16]byte
+
+func (x Gf128) words() (uint64, uint64) {
+	return binary.BigEndian.Uint64(x[:8]), binary.BigEndian.Uint64(x[8:])
+}
+
+func gf128FromWords(hi, lo uint64) Gf128 {
+	var r Gf128
+	binary.BigEndian.PutUint64(r[:8], hi)
+	binary.BigEndian.PutUint64(r[8:], lo)
+	return r
+}
+
+// Add returns x + y, which in GF(2^128) is XOR.
+func (x Gf128) Add(y Gf128) Gf128 {
+	for i := range x {
+		x[i] ^= y[i]
+	}
+	return x
+}
+
+// Mul returns x * y according to Algorithm 1 of NIST SP 800-38D.
+func (x Gf128) Mul(y Gf128) Gf128 {
+	xHi, xLo := x.words()
+	vHi, vLo := y.words()
+	var zHi, zLo uint64
+	for i := 0; i < 128; i++ {
+		var bit uint64
+		if i < 64 {
+			bit = (xHi >> (63 - i)) & 1
+		} else {
+			bit = (xLo >> (127 - i)) & 1
+		}
+		if bit != 0 {
+			zHi ^= vHi
+			zLo ^= vLo
+		}
+		vHi, vLo = gf128MulX(vHi, vLo)
+	}
+	return gf128FromWords(zHi, zLo)
+}
+
+// MulX returns x multiplied by the polynomial x, which is a right shift in GCM bit order.
+func (x Gf128) MulX() Gf128 {
+	return gf128FromWords(gf128MulX(x.words()))
+}
+
+// gf128MulX shifts the GCM-ordered element right by one bit (multiplication by x), reducing if the
+// x^127 coefficient falls off the end.
+func gf128MulX(hi, lo uint64) (uint64, uint64) {
+	carry := lo & 1
+	lo = lo>>1 | hi<<63
+	hi >>= 1
+	if carry != 0 {
+		hi ^= 0xe1 << 56
+	}
+	return hi, lo
+}
+
+// double multiplies a block by x in GF(2^128) using the big-endian convention of CMAC (RFC 4493),
+// where the most significant bit of the first byte is the coefficient of x^127.
+func double(b [16]byte) [16]byte {
+	var r [16]byte
+	for i := 0; i < 15; i++ {
+		r[i] = b[i]<<1 | b[i+1]>>7
+	}
+	r[15] = b[15] << 1
+	r[15] ^= 0x87 & -(b[0] >> 7)
+	return r
+}
+
+// doubleLE multiplies a block by x in GF(2^128) using the little-endian convention of XTS
+// (IEEE 1619), where the least significant bit of the first byte is the coefficient of x^0.
+func doubleLE(b [16]byte) [16]byte {
+	var r [16]byte
+	r[0] = b[0] << 1
+	for i := 1; i < 16; i++ {
+		r[i] = b[i]<<1 | b[i-1]>>7
+	}
+	r[0] ^= 0x87 & -(b[15] >> 7)
+	return r
+}
@@ -0,0 +1,29 @@
+package aes_test
+
+import (
+	"cryptopals/utils/aes"
+	"testing"
+)
+
+func TestGf128(t *testing.T) {
+	var one, x aes.Gf128
+	one[0] = 0x80
+	x[0] = 0x40
+	a := aes.Gf128{0x66, 0xe9, 0x4b, 0xd4, 0xef, 0x8a, 0x2c, 0x3b, 0x88, 0x4c, 0xfa, 0x59, 0xca, 0x34, 0x2b, 0x2e}
+	b := aes.Gf128{0x03, 0x88, 0xda, 0xce, 0x60, 0xb6, 0xa3, 0x92, 0xf3, 0x28, 0xc2, 0xb9, 0x71, 0xb2, 0xfe, 0x78}
+
+	if a.Mul(one) != a {
+		t.Errorf("Want %x got %x", a, a.Mul(one))
+	}
+	if a.Mul(b) != b.Mul(a) {
+		t.Errorf("Multiplication is not commutative: %x vs %x", a.Mul(b), b.Mul(a))
+	}
+	if a.Mul(x) != a.MulX() {
+		t.Errorf("Want %x got %x", a.Mul(x), a.MulX())
+	}
+	// GHASH_H(C) for test case 2 is C * H
+	expected := aes.Gf128{0x5e, 0x2e, 0xc7, 0x46, 0x91, 0x70, 0x62, 0x88, 0x2c, 0x85, 0xb0, 0x68, 0x53, 0x53, 0xde, 0xb7}
+	if b.Mul(a) != expected {
+		t.Errorf("Want %x got %x", expected, b.Mul(a))
+	}
+}
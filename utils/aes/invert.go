@@ -0,0 +1,71 @@
+package aes
+
+import "fmt"
+
+// rcon returns the round constant used for the i'th application of the key schedule core
+// (starting from 1), in the most significant byte of the word.
+func rcon(i int) uint32 {
+	rc := (byte)(0x01)
+	for j := 1; j < i; j++ {
+		rc = gfmult(2, rc)
+	}
+	return (uint32)(rc) << 24
+}
+
+// invertKeyExpansion runs the FIPS-197 key expansion backwards. words holds at least nk
+// consecutive words of the expanded key, the first of which is word number start. It returns the
+// first nk words of the expansion, i.e. the cipher key.
+func invertKeyExpansion(words []uint32, start int, nk int) []uint32 {
+	w := make([]uint32, start+nk)
+	copy(w[start:], words[:nk])
+	// w[i] = w[i-nk] ^ t(w[i-1]), so w[i-nk] = w[i] ^ t(w[i-1])
+	for i := start + nk - 1; i >= nk; i-- {
+		t := w[i-1]
+		if i%nk == 0 {
+			t = subWord(rotWord(t)) ^ rcon(i/nk)
+		} else if nk > 6 && i%nk == 4 {
+			t = subWord(t)
+		}
+		w[i-nk] = w[i] ^ t
+	}
+	return w[:nk]
+}
+
+// putKeyWords writes big-endian words into a key.
+func putKeyWords(key []byte, w []uint32) {
+	for i := range key {
+		key[i] = (byte)(w[i/4] >> (8 * (3 - (i % 4))))
+	}
+}
+
+// InvertKeySchedule recovers an AES-128 key from its round key for the given round (0 to 10).
+func InvertKeySchedule(roundKey [16]byte, round int) (Key128, error) {
+	if round < 0 || round > 10 {
+		return Key128{}, fmt.Errorf("invalid AES-128 round %d", round)
+	}
+	var k Key128
+	putKeyWords(k.data[:], invertKeyExpansion(roundKeyWords([][16]byte{roundKey}), 4*round, 4))
+	return k, nil
+}
+
+// InvertKeySchedule192 recovers an AES-192 key from two consecutive round keys, the first of which
+// is for the given round (0 to 11).
+func InvertKeySchedule192(roundKeys [2][16]byte, round int) (Key192, error) {
+	if round < 0 || round > 11 {
+		return Key192{}, fmt.Errorf("invalid AES-192 round %d", round)
+	}
+	var k Key192
+	putKeyWords(k.data[:], invertKeyExpansion(roundKeyWords(roundKeys[:]), 4*round, 6))
+	return k, nil
+}
+
+// InvertKeySchedule256 recovers an AES-256 key from two consecutive round keys, the first of which
+// is for the given round (0 to 13).
+func InvertKeySchedule256(roundKeys [2][16]byte, round int) (Key256, error) {
+	if round < 0 || round > 13 {
+		return Key256{}, fmt.Errorf("invalid AES-256 round %d", round)
+	}
+	var k Key256
+	putKeyWords(k.data[:], invertKeyExpansion(roundKeyWords(roundKeys[:]), 4*round, 8))
+	return k, nil
+}
@@ -0,0 +1,83 @@
+package aes_test
+
+import (
+	"cryptopals/utils/aes"
+	"math/rand"
+	"testing"
+)
+
+func scheduleOf(key aes.Key) [][16]byte {
+	var roundKeys [][16]byte
+	for roundKey := range key.KeySchedule() {
+		roundKeys = append(roundKeys, roundKey)
+	}
+	return roundKeys
+}
+
+func TestInvertKeySchedule(t *testing.T) {
+	key := aes.NewKey128([16]byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c})
+	// FIPS-197 Appendix A.1, words 40 to 43
+	lastRoundKey := [16]byte{0xd0, 0x14, 0xf9, 0xa8, 0xc9, 0xee, 0x25, 0x89, 0xe1, 0x3f, 0x0c, 0xc8, 0xb6, 0x63, 0x0c, 0xa6}
+
+	recovered, err := aes.InvertKeySchedule(lastRoundKey, 10)
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	if recovered != key {
+		t.Errorf("Want %v got %v", key, recovered)
+	}
+
+	rng := rand.New(rand.NewSource(3))
+	for i := 0; i < 20; i++ {
+		key := randomKey(t, rng, 16).(aes.Key128)
+		for round, roundKey := range scheduleOf(key) {
+			recovered, err := aes.InvertKeySchedule(roundKey, round)
+			if err != nil {
+				t.Fatalf("Want nil got %v", err)
+			}
+			if recovered != key {
+				t.Errorf("Round %d: want %v got %v", round, key, recovered)
+			}
+		}
+	}
+
+	for _, round := range []int{-1, 11} {
+		if _, err := aes.InvertKeySchedule(lastRoundKey, round); err == nil {
+			t.Errorf("Round %d: want err got nil", round)
+		}
+	}
+}
+
+func TestInvertKeySchedule192(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	for i := 0; i < 20; i++ {
+		key := randomKey(t, rng, 24).(aes.Key192)
+		schedule := scheduleOf(key)
+		for round := 0; round < len(schedule)-1; round++ {
+			recovered, err := aes.InvertKeySchedule192([2][16]byte{schedule[round], schedule[round+1]}, round)
+			if err != nil {
+				t.Fatalf("Want nil got %v", err)
+			}
+			if recovered != key {
+				t.Errorf("Round %d: want %v got %v", round, key, recovered)
+			}
+		}
+	}
+}
+
+func TestInvertKeySchedule256(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	for i := 0; i < 20; i++ {
+		key := randomKey(t, rng, 32).(aes.Key256)
+		schedule := scheduleOf(key)
+		for round := 0; round < len(schedule)-1; round++ {
+			recovered, err := aes.InvertKeySchedule256([2][16]byte{schedule[round], schedule[round+1]}, round)
+			if err != nil {
+				t.Fatalf("Want nil got %v", err)
+			}
+			if recovered != key {
+				t.Errorf("Round %d: want %v got %v", round, key, recovered)
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package aes
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// defaultIV is the initial value from RFC 3394, section 2.2.3.1.
+var defaultIV = [8]byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+// alternativeIVPrefix is the constant half of the alternative initial value from RFC 5649,
+// section 3. The other half is the length of the key data.
+var alternativeIVPrefix = [4]byte{0xa6, 0x59, 0x59, 0xa6}
+
+// IntegrityError is returned when unwrapping fails its integrity check, e.g. because the wrapped
+// key or the key-encryption key is wrong. IV holds the initial value that was recovered.
+type IntegrityError struct {
+	IV [8]byte
+}
+
+// Error implements the error interface.
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("key unwrap integrity check failed (recovered IV %x)", e.IV)
+}
+
+// wrap runs the wrapping process from RFC 3394, section 2.2.1, over the 64-bit blocks of data.
+func wrap(kek *ExpandedKey, iv [8]byte, data []byte) []byte {
+	n := len(data) / 8
+	out := make([]byte, 8+len(data))
+	copy(out[8:], data)
+	a := iv
+	var b [16]byte
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			r := out[8*i : 8*i+8]
+			copy(b[:8], a[:])
+			copy(b[8:], r)
+			b = kek.Encrypt(b)
+			copy(a[:], b[:8])
+			t := binary.BigEndian.Uint64(a[:]) ^ uint64(n*j+i)
+			binary.BigEndian.PutUint64(a[:], t)
+			copy(r, b[8:])
+		}
+	}
+	copy(out[:8], a[:])
+	return out
+}
+
+// unwrap runs the unwrapping process from RFC 3394, section 2.2.2, returning the recovered initial
+// value and key data without checking either.
+func unwrap(kek *ExpandedKey, ciphertext []byte) ([8]byte, []byte) {
+	n := len(ciphertext)/8 - 1
+	out := make([]byte, 8*n)
+	copy(out, ciphertext[8:])
+	var a [8]byte
+	copy(a[:], ciphertext[:8])
+	var b [16]byte
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			r := out[8*(i-1) : 8*i]
+			t := binary.BigEndian.Uint64(a[:]) ^ uint64(n*j+i)
+			binary.BigEndian.PutUint64(b[:8], t)
+			copy(b[8:], r)
+			b = kek.Decrypt(b)
+			copy(a[:], b[:8])
+			copy(r, b[8:])
+		}
+	}
+	return a, out
+}
+
+// Wrap wraps key data with the key-encryption key according to RFC 3394. The key data must be a
+// multiple of 8 bytes, and at least 16 bytes long.
+func Wrap(kek Key, plaintext []byte) ([]byte, error) {
+	if len(plaintext) < 16 || len(plaintext)%8 != 0 {
+		return nil, fmt.Errorf("key wrap requires a multiple of 8 bytes (at least 16), got %d", len(plaintext))
+	}
+	expanded, err := Expand(kek)
+	if err != nil {
+		return nil, err
+	}
+	return wrap(expanded, defaultIV, plaintext), nil
+}
+
+// Unwrap unwraps key data with the key-encryption key according to RFC 3394. If the integrity
+// check fails, the error is an *IntegrityError.
+func Unwrap(kek Key, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < 24 || len(ciphertext)%8 != 0 {
+		return nil, fmt.Errorf("key unwrap requires a multiple of 8 bytes (at least 24), got %d", len(ciphertext))
+	}
+	expanded, err := Expand(kek)
+	if err != nil {
+		return nil, err
+	}
+	iv, plaintext := unwrap(expanded, ciphertext)
+	if iv != defaultIV {
+		return nil, &IntegrityError{IV: iv}
+	}
+	return plaintext, nil
+}
+
+// WrapPad wraps key data of any non-zero length with the key-encryption key according to RFC 5649.
+func WrapPad(kek Key, plaintext []byte) ([]byte, error) {
+	if len(plaintext) == 0 || uint64(len(plaintext)) > 0xffffffff {
+		return nil, fmt.Errorf("invalid key data length %d", len(plaintext))
+	}
+	expanded, err := Expand(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	var iv [8]byte
+	copy(iv[:4], alternativeIVPrefix[:])
+	binary.BigEndian.PutUint32(iv[4:], uint32(len(plaintext)))
+	padded := make([]byte, (len(plaintext)+7)/8*8)
+	copy(padded, plaintext)
+
+	if len(padded) == 8 {
+		// A single block of key data is encrypted directly
+		var b [16]byte
+		copy(b[:8], iv[:])
+		copy(b[8:], padded)
+		b = expanded.Encrypt(b)
+		return b[:], nil
+	}
+	return wrap(expanded, iv, padded), nil
+}
+
+// UnwrapPad unwraps key data with the key-encryption key according to RFC 5649. If the integrity
+// check (including the length and padding checks) fails, the error is an *IntegrityError.
+func UnwrapPad(kek Key, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < 16 || len(ciphertext)%8 != 0 {
+		return nil, fmt.Errorf("key unwrap requires a multiple of 8 bytes (at least 16), got %d", len(ciphertext))
+	}
+	expanded, err := Expand(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	var iv [8]byte
+	var padded []byte
+	if len(ciphertext) == 16 {
+		var b [16]byte
+		copy(b[:], ciphertext)
+		b = expanded.Decrypt(b)
+		copy(iv[:], b[:8])
+		padded = b[8:]
+	} else {
+		iv, padded = unwrap(expanded, ciphertext)
+	}
+
+	if !bytes.Equal(iv[:4], alternativeIVPrefix[:]) {
+		return nil, &IntegrityError{IV: iv}
+	}
+	mli := int(binary.BigEndian.Uint32(iv[4:]))
+	if mli <= len(padded)-8 || mli > len(padded) {
+		return nil, &IntegrityError{IV: iv}
+	}
+	for _, b := range padded[mli:] {
+		if b != 0 {
+			return nil, &IntegrityError{IV: iv}
+		}
+	}
+	return padded[:mli], nil
+}
@@ -0,0 +1,157 @@
+package aes_test
+
+import (
+	"bytes"
+	"cryptopals/utils/aes"
+	"errors"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	// RFC 3394, section 4
+	cases := []struct {
+		name       string
+		kek        string
+		plaintext  string
+		ciphertext string
+	}{
+		{
+			name:       "128KeyDataWith128KEK",
+			kek:        "000102030405060708090A0B0C0D0E0F",
+			plaintext:  "00112233445566778899AABBCCDDEEFF",
+			ciphertext: "1FA68B0A8112B447AEF34BD8FB5A7B829D3E862371D2CFE5",
+		},
+		{
+			name:       "128KeyDataWith192KEK",
+			kek:        "000102030405060708090A0B0C0D0E0F1011121314151617",
+			plaintext:  "00112233445566778899AABBCCDDEEFF",
+			ciphertext: "96778B25AE6CA435F92B5B97C050AED2468AB8A17AD84E5D",
+		},
+		{
+			name:       "128KeyDataWith256KEK",
+			kek:        "000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F",
+			plaintext:  "00112233445566778899AABBCCDDEEFF",
+			ciphertext: "64E8C3F9CE0F5BA263E9777905818A2A93C8191E7D6E8AE7",
+		},
+		{
+			name:       "256KeyDataWith256KEK",
+			kek:        "000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F",
+			plaintext:  "00112233445566778899AABBCCDDEEFF000102030405060708090A0B0C0D0E0F",
+			ciphertext: "28C9F404C4B810F4CBCCB35CFB87F8263F5786E2D80ED326CBC7F0E71A99F43BFB988B9B7A02DD21",
+		},
+	}
+
+	for _, c := range cases {
+		// loop variable c will be captured by reference, so we shadow it with a new variable also
+		// called c
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			kek, err := aes.NewKey(unhex(t, c.kek))
+			if err != nil {
+				t.Fatalf("Want nil got %v", err)
+			}
+			plaintext := unhex(t, c.plaintext)
+			ciphertext := unhex(t, c.ciphertext)
+
+			wrapped, err := aes.Wrap(kek, plaintext)
+			if err != nil {
+				t.Errorf("Want nil got %v", err)
+			}
+			if !bytes.Equal(wrapped, ciphertext) {
+				t.Errorf("Want %x got %x", ciphertext, wrapped)
+			}
+
+			// Now do it in reverse
+			unwrapped, err := aes.Unwrap(kek, ciphertext)
+			if err != nil {
+				t.Errorf("Want nil got %v", err)
+			}
+			if !bytes.Equal(unwrapped, plaintext) {
+				t.Errorf("Want %x got %x", plaintext, unwrapped)
+			}
+
+			ciphertext[len(ciphertext)-1] ^= 0x01
+			_, err = aes.Unwrap(kek, ciphertext)
+			var integrityErr *aes.IntegrityError
+			if !errors.As(err, &integrityErr) {
+				t.Errorf("Want *IntegrityError got %v", err)
+			}
+		})
+	}
+}
+
+func TestWrapPad(t *testing.T) {
+	// RFC 5649, section 6
+	kek, err := aes.NewKey(unhex(t, "5840df6e29b02af1ab493b705bf16ea1ae8338f4dcc176a8"))
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	cases := []struct {
+		name       string
+		plaintext  string
+		ciphertext string
+	}{
+		{
+			name:       "20Octets",
+			plaintext:  "c37b7e6492584340bed12207808941155068f738",
+			ciphertext: "138bdeaa9b8fa7fc61f97742e72248ee5ae6ae5360d1ae6a5f54f373fa543b6a",
+		},
+		{
+			name:       "7Octets",
+			plaintext:  "466f7250617369",
+			ciphertext: "afbeb0f07dfbf5419200f2ccb50bb24f",
+		},
+	}
+
+	for _, c := range cases {
+		// loop variable c will be captured by reference, so we shadow it with a new variable also
+		// called c
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			plaintext := unhex(t, c.plaintext)
+			ciphertext := unhex(t, c.ciphertext)
+
+			wrapped, err := aes.WrapPad(kek, plaintext)
+			if err != nil {
+				t.Errorf("Want nil got %v", err)
+			}
+			if !bytes.Equal(wrapped, ciphertext) {
+				t.Errorf("Want %x got %x", ciphertext, wrapped)
+			}
+
+			// Now do it in reverse
+			unwrapped, err := aes.UnwrapPad(kek, ciphertext)
+			if err != nil {
+				t.Errorf("Want nil got %v", err)
+			}
+			if !bytes.Equal(unwrapped, plaintext) {
+				t.Errorf("Want %x got %x", plaintext, unwrapped)
+			}
+
+			ciphertext[0] ^= 0x01
+			_, err = aes.UnwrapPad(kek, ciphertext)
+			var integrityErr *aes.IntegrityError
+			if !errors.As(err, &integrityErr) {
+				t.Errorf("Want *IntegrityError got %v", err)
+			}
+		})
+	}
+}
+
+func TestWrapInvalidLength(t *testing.T) {
+	kek := aes.NewKey128([16]byte{})
+	if _, err := aes.Wrap(kek, make([]byte, 12)); err == nil {
+		t.Errorf("Want err got nil")
+	}
+	if _, err := aes.Unwrap(kek, make([]byte, 16)); err == nil {
+		t.Errorf("Want err got nil")
+	}
+	if _, err := aes.WrapPad(kek, nil); err == nil {
+		t.Errorf("Want err got nil")
+	}
+	if _, err := aes.UnwrapPad(kek, make([]byte, 8)); err == nil {
+		t.Errorf("Want err got nil")
+	}
+}
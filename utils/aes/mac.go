@@ -0,0 +1,127 @@
+package aes
+
+import "hash"
+
+// cbcMac is an incremental CBC-MAC or CMAC computation.
+type cbcMac struct {
+	key *ExpandedKey
+	iv  [16]byte
+	// x is the chaining value after all the blocks processed so far
+	x [16]byte
+	// buf holds the bytes of the last, unprocessed block
+	buf []byte
+	// cmac subkeys, if this is a CMAC
+	cmac   bool
+	k1, k2 [16]byte
+}
+
+// NewCbcMac returns a hash.Hash which computes the raw CBC-MAC of the data written to it: the last
+// block of its CBC encryption under key and iv. A trailing partial block is padded with zeros, and if
+// no data is written the tag is iv.
+func NewCbcMac(key Key, iv [16]byte) (hash.Hash, error) {
+	expanded, err := Expand(key)
+	if err != nil {
+		return nil, err
+	}
+	m := &cbcMac{key: expanded, iv: iv}
+	m.Reset()
+	return m, nil
+}
+
+// NewCmac returns a hash.Hash which computes the AES-CMAC (RFC 4493) of the data written to it.
+func NewCmac(key Key) (hash.Hash, error) {
+	expanded, err := Expand(key)
+	if err != nil {
+		return nil, err
+	}
+	// Generate the subkeys from L = E(0)
+	k1 := double(expanded.Encrypt([16]byte{}))
+	m := &cbcMac{key: expanded, cmac: true, k1: k1, k2: double(k1)}
+	m.Reset()
+	return m, nil
+}
+
+// CbcMac computes the raw CBC-MAC of msg under key and iv, zero-padding a trailing partial block.
+func CbcMac(key Key, iv [16]byte, msg []byte) ([16]byte, error) {
+	m, err := NewCbcMac(key, iv)
+	if err != nil {
+		return [16]byte{}, err
+	}
+	m.Write(msg)
+	var tag [16]byte
+	copy(tag[:], m.Sum(nil))
+	return tag, nil
+}
+
+// Cmac computes the AES-CMAC (RFC 4493) of msg under key.
+func Cmac(key Key, msg []byte) ([16]byte, error) {
+	m, err := NewCmac(key)
+	if err != nil {
+		return [16]byte{}, err
+	}
+	m.Write(msg)
+	var tag [16]byte
+	copy(tag[:], m.Sum(nil))
+	return tag, nil
+}
+
+// chain folds a block into the chaining value.
+func (m *cbcMac) chain(x [16]byte, block []byte) [16]byte {
+	for i := range x {
+		x[i] ^= block[i]
+	}
+	return m.key.Encrypt(x)
+}
+
+// Write adds more data to the running MAC. It never returns an error.
+func (m *cbcMac) Write(p []byte) (int, error) {
+	for _, b := range p {
+		// CMAC treats the last block specially, so a full block is only processed once we know
+		// more data follows it
+		if len(m.buf) == 16 {
+			m.x = m.chain(m.x, m.buf)
+			m.buf = m.buf[:0]
+		}
+		m.buf = append(m.buf, b)
+	}
+	return len(p), nil
+}
+
+// Sum appends the current tag to b without changing the underlying state.
+func (m *cbcMac) Sum(b []byte) []byte {
+	var last [16]byte
+	copy(last[:], m.buf)
+	x := m.x
+	switch {
+	case m.cmac && len(m.buf) == 16:
+		for i := range last {
+			last[i] ^= m.k1[i]
+		}
+		x = m.chain(x, last[:])
+	case m.cmac:
+		last[len(m.buf)] = 0x80
+		for i := range last {
+			last[i] ^= m.k2[i]
+		}
+		x = m.chain(x, last[:])
+	case len(m.buf) > 0:
+		x = m.chain(x, last[:])
+	}
+	return append(b, x[:]...)
+}
+
+// Reset resets the MAC to its initial state.
+func (m *cbcMac) Reset() {
+	m.x = m.iv
+	m.buf = make([]byte, 0, 16)
+}
+
+// Size returns the length of the tag, which is always 16 bytes.
+func (m *cbcMac) Size() int {
+	return 16
+}
+
+// BlockSize returns the AES block size, which is always 16 bytes.
+func (m *cbcMac) BlockSize() int {
+	return 16
+}
@@ -0,0 +1,105 @@
+package aes_test
+
+import (
+	"bytes"
+	"cryptopals/utils/aes"
+	"io"
+	"testing"
+)
+
+func TestCmac(t *testing.T) {
+	// RFC 4493, section 4
+	cases := []struct {
+		name     string
+		length   int
+		expected string
+	}{
+		{name: "Example1", length: 0, expected: "bb1d6929e95937287fa37d129b756746"},
+		{name: "Example2", length: 16, expected: "070a16b46b4d4144f79bdd9dd04a287c"},
+		{name: "Example3", length: 40, expected: "dfa66747de9ae63030ca32611497c827"},
+		{name: "Example4", length: 64, expected: "51f0bebf7e3b9d92fc49741779363cfe"},
+	}
+
+	for _, c := range cases {
+		// loop variable c will be captured by reference, so we shadow it with a new variable also
+		// called c
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			expected := unhex(t, c.expected)
+			msg := sp80038aPt[:c.length]
+
+			tag, err := aes.Cmac(sp80038aKey, msg)
+			if err != nil {
+				t.Fatalf("Want nil got %v", err)
+			}
+			if !bytes.Equal(tag[:], expected) {
+				t.Errorf("Want %x got %x", expected, tag)
+			}
+
+			// Write the message in uneven pieces, checking that Sum doesn't disturb the state
+			h, err := aes.NewCmac(sp80038aKey)
+			if err != nil {
+				t.Fatalf("Want nil got %v", err)
+			}
+			for i := 0; i < len(msg); i += 7 {
+				end := i + 7
+				if end > len(msg) {
+					end = len(msg)
+				}
+				h.Write(msg[i:end])
+				h.Sum(nil)
+			}
+			if sum := h.Sum(nil); !bytes.Equal(sum, expected) {
+				t.Errorf("Want %x got %x", expected, sum)
+			}
+		})
+	}
+}
+
+func TestCbcMac(t *testing.T) {
+	iv := sp80038aIv
+
+	// The CBC-MAC is the last block of the CBC encryption
+	encrypted := new(bytes.Buffer)
+	if _, err := io.Copy(encrypted, aes.Cbc(sp80038aKey, iv, true, bytes.NewReader(sp80038aPt))); err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	expected := encrypted.Bytes()[len(sp80038aPt)-16:]
+
+	tag, err := aes.CbcMac(sp80038aKey, iv, sp80038aPt)
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	if !bytes.Equal(tag[:], expected) {
+		t.Errorf("Want %x got %x", expected, tag)
+	}
+
+	h, err := aes.NewCbcMac(sp80038aKey, iv)
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	h.Write(sp80038aPt[:5])
+	h.Write(sp80038aPt[5:])
+	if sum := h.Sum(nil); !bytes.Equal(sum, expected) {
+		t.Errorf("Want %x got %x", expected, sum)
+	}
+
+	// A trailing partial block is zero padded
+	short, err := aes.CbcMac(sp80038aKey, iv, sp80038aPt[:20])
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	padded, err := aes.CbcMac(sp80038aKey, iv, append(append([]byte{}, sp80038aPt[:20]...), make([]byte, 12)...))
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	if short != padded {
+		t.Errorf("Want %x got %x", padded, short)
+	}
+
+	h.Reset()
+	if sum := h.Sum(nil); !bytes.Equal(sum, iv[:]) {
+		t.Errorf("Want %x got %x", iv, sum)
+	}
+}
@@ -0,0 +1,73 @@
+package aes
+
+import (
+	"cryptopals/utils/padding"
+)
+
+// An Option configures an Ecb or Cbc engine.
+type Option func(*options)
+
+type options struct {
+	padding padding.Scheme
+}
+
+// WithPadding makes an engine pad the plaintext with the given scheme when encrypting, and check
+// and strip the padding when decrypting. If the padding is malformed, the decryptor returns
+// padding.ErrInvalidPadding instead of io.EOF.
+func WithPadding(scheme padding.Scheme) Option {
+	return func(o *options) {
+		o.padding = scheme
+	}
+}
+
+func makeOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// blockOutput sends the blocks produced by an engine to its output channel. When padding is to be
+// stripped, the most recent block is held back until finish is called at the end of the data.
+type blockOutput struct {
+	o     chan<- byte
+	unpad padding.Scheme
+	held  []byte
+}
+
+func newBlockOutput(o chan<- byte, encrypt bool, opts options) *blockOutput {
+	out := &blockOutput{o: o}
+	if !encrypt {
+		out.unpad = opts.padding
+	}
+	return out
+}
+
+func (out *blockOutput) send(block [16]byte) {
+	if out.unpad == nil {
+		for _, b := range block {
+			out.o <- b
+		}
+		return
+	}
+	for _, b := range out.held {
+		out.o <- b
+	}
+	out.held = append(out.held[:0], block[:]...)
+}
+
+// finish checks the padding on the held back block and sends what is left of it.
+func (out *blockOutput) finish() error {
+	if out.unpad == nil {
+		return nil
+	}
+	pad, err := out.unpad.Check(16, out.held)
+	if err != nil {
+		return err
+	}
+	for _, b := range out.held[:len(out.held)-pad] {
+		out.o <- b
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package aes_test
+
+import (
+	"bytes"
+	"cryptopals/utils/aes"
+	"cryptopals/utils/padding"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestEnginePadding(t *testing.T) {
+	schemes := []padding.Scheme{padding.PKCS7, padding.ANSIX923, padding.ISO10126, padding.ISO7816, padding.ZERO}
+	engines := []struct {
+		name   string
+		engine func(encrypt bool, r io.Reader, opts ...aes.Option) io.Reader
+	}{
+		{"Ecb", func(encrypt bool, r io.Reader, opts ...aes.Option) io.Reader {
+			return aes.Ecb(sp80038aKey, encrypt, r, opts...)
+		}},
+		{"Cbc", func(encrypt bool, r io.Reader, opts ...aes.Option) io.Reader {
+			return aes.Cbc(sp80038aKey, sp80038aIv, encrypt, r, opts...)
+		}},
+	}
+
+	for _, e := range engines {
+		for _, scheme := range schemes {
+			for _, n := range []int{0, 1, 15, 16, 17, 63} {
+				message := bytes.Repeat([]byte{0x5a}, n)
+				encrypted := new(bytes.Buffer)
+				if _, err := io.Copy(encrypted, e.engine(true, iotest.HalfReader(bytes.NewReader(message)), aes.WithPadding(scheme))); err != nil {
+					t.Fatalf("%s %T length %d: want nil got %v", e.name, scheme, n, err)
+				}
+
+				// Decrypting without the option leaves the padding for the scheme to strip
+				padded := new(bytes.Buffer)
+				decrypted := new(bytes.Buffer)
+				if _, err := io.Copy(padded, e.engine(false, bytes.NewReader(encrypted.Bytes()))); err != nil {
+					t.Fatalf("%s %T length %d: want nil got %v", e.name, scheme, n, err)
+				}
+				if _, err := io.Copy(decrypted, scheme.Unpad(16, bytes.NewReader(padded.Bytes()))); err != nil {
+					t.Errorf("%s %T length %d: want nil got %v", e.name, scheme, n, err)
+				}
+				if !bytes.Equal(decrypted.Bytes(), message) {
+					t.Errorf("%s %T length %d: want %x got %x", e.name, scheme, n, message, decrypted.Bytes())
+				}
+
+				decrypted.Reset()
+				if _, err := io.Copy(decrypted, e.engine(false, iotest.HalfReader(bytes.NewReader(encrypted.Bytes())), aes.WithPadding(scheme))); err != nil {
+					t.Errorf("%s %T length %d: want nil got %v", e.name, scheme, n, err)
+				}
+				if !bytes.Equal(decrypted.Bytes(), message) {
+					t.Errorf("%s %T length %d: want %x got %x", e.name, scheme, n, message, decrypted.Bytes())
+				}
+			}
+		}
+
+		// Decrypting the SP 800-38A plaintext as if it were ciphertext doesn't give valid PKCS#7 padding
+		_, err := io.Copy(io.Discard, e.engine(false, bytes.NewReader(sp80038aPt), aes.WithPadding(padding.PKCS7)))
+		if err != padding.ErrInvalidPadding {
+			t.Errorf("%s: want %v got %v", e.name, padding.ErrInvalidPadding, err)
+		}
+
+		_, err = io.Copy(io.Discard, e.engine(false, bytes.NewReader(sp80038aPt[:20]), aes.WithPadding(padding.PKCS7)))
+		if err == nil {
+			t.Errorf("%s: want an error for a partial block got nil", e.name)
+		}
+	}
+}
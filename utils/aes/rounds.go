@@ -0,0 +1,70 @@
+package aes
+
+import "fmt"
+
+// Step identifies one of the steps of the Rijndael round function.
+type Step int
+
+const (
+	// ADDROUNDKEY is the AddRoundKey step.
+	ADDROUNDKEY Step = iota
+	// SUBBYTES is the SubBytes step.
+	SUBBYTES
+	// SHIFTROWS is the ShiftRows step.
+	SHIFTROWS
+	// MIXCOLUMNS is the MixColumns step.
+	MIXCOLUMNS
+)
+
+// String returns the FIPS-197 name of the step.
+func (s Step) String() string {
+	switch s {
+	case ADDROUNDKEY:
+		return "AddRoundKey"
+	case SUBBYTES:
+		return "SubBytes"
+	case SHIFTROWS:
+		return "ShiftRows"
+	case MIXCOLUMNS:
+		return "MixColumns"
+	default:
+		return fmt.Sprintf("Step(%d)", int(s))
+	}
+}
+
+// Tracer receives the state after each step of the cipher. Rounds are numbered in the order they
+// are processed: round 0 is the initial AddRoundKey, and the last round has no MixColumns. When
+// decrypting, the steps are the inverse ones, in the order the inverse cipher applies them.
+type Tracer func(round int, step Step, state [16]byte)
+
+// RijndaelRounds performs a reduced-round AES operation on a single block of data. Encryption runs
+// the initial AddRoundKey followed by `rounds` rounds, the last of which omits MixColumns, and
+// decryption inverts exactly that. rounds must be between 1 and the full number of rounds for the
+// key.
+func RijndaelRounds(key Key, encrypt bool, rounds int, data [16]byte) (*[16]byte, error) {
+	expanded, err := Expand(key)
+	if err != nil {
+		return nil, err
+	}
+	reduced, err := expanded.reduced(rounds)
+	if err != nil {
+		return nil, err
+	}
+	state := reduced.Crypt(encrypt, data)
+	return &state, nil
+}
+
+// RijndaelTrace is like RijndaelRounds, but reports the state after every step to trace. It always
+// runs the step-by-step reference implementation.
+func RijndaelTrace(key Key, encrypt bool, rounds int, data [16]byte, trace Tracer) (*[16]byte, error) {
+	expanded, err := Expand(key)
+	if err != nil {
+		return nil, err
+	}
+	reduced, err := expanded.reduced(rounds)
+	if err != nil {
+		return nil, err
+	}
+	state := rijndaelReference(reduced.roundKeys, encrypt, data, trace)
+	return &state, nil
+}
@@ -0,0 +1,148 @@
+package aes_test
+
+import (
+	"bytes"
+	"cryptopals/utils/aes"
+	"math/rand"
+	"testing"
+)
+
+type traceStep struct {
+	round int
+	step  aes.Step
+}
+
+func TestRijndaelTrace(t *testing.T) {
+	// FIPS-197 Appendix C.1
+	key := aes.NewKey128([16]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f})
+	data := [16]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}
+	output := [16]byte{0x69, 0xc4, 0xe0, 0xd8, 0x6a, 0x7b, 0x04, 0x30, 0xd8, 0xcd, 0xb7, 0x80, 0x70, 0xb4, 0xc5, 0x5a}
+
+	cases := []struct {
+		name     string
+		encrypt  bool
+		input    [16]byte
+		output   [16]byte
+		expected map[traceStep][16]byte
+	}{
+		{
+			name:    "Cipher",
+			encrypt: true,
+			input:   data,
+			output:  output,
+			expected: map[traceStep][16]byte{
+				{0, aes.ADDROUNDKEY}:  {0x00, 0x10, 0x20, 0x30, 0x40, 0x50, 0x60, 0x70, 0x80, 0x90, 0xa0, 0xb0, 0xc0, 0xd0, 0xe0, 0xf0},
+				{1, aes.SUBBYTES}:     {0x63, 0xca, 0xb7, 0x04, 0x09, 0x53, 0xd0, 0x51, 0xcd, 0x60, 0xe0, 0xe7, 0xba, 0x70, 0xe1, 0x8c},
+				{1, aes.SHIFTROWS}:    {0x63, 0x53, 0xe0, 0x8c, 0x09, 0x60, 0xe1, 0x04, 0xcd, 0x70, 0xb7, 0x51, 0xba, 0xca, 0xd0, 0xe7},
+				{1, aes.MIXCOLUMNS}:   {0x5f, 0x72, 0x64, 0x15, 0x57, 0xf5, 0xbc, 0x92, 0xf7, 0xbe, 0x3b, 0x29, 0x1d, 0xb9, 0xf9, 0x1a},
+				{1, aes.ADDROUNDKEY}:  {0x89, 0xd8, 0x10, 0xe8, 0x85, 0x5a, 0xce, 0x68, 0x2d, 0x18, 0x43, 0xd8, 0xcb, 0x12, 0x8f, 0xe4},
+				{10, aes.ADDROUNDKEY}: output,
+			},
+		},
+		{
+			name:    "InverseCipher",
+			encrypt: false,
+			input:   output,
+			output:  data,
+			expected: map[traceStep][16]byte{
+				{0, aes.ADDROUNDKEY}:  {0x7a, 0xd5, 0xfd, 0xa7, 0x89, 0xef, 0x4e, 0x27, 0x2b, 0xca, 0x10, 0x0b, 0x3d, 0x9f, 0xf5, 0x9f},
+				{1, aes.SHIFTROWS}:    {0x7a, 0x9f, 0x10, 0x27, 0x89, 0xd5, 0xf5, 0x0b, 0x2b, 0xef, 0xfd, 0x9f, 0x3d, 0xca, 0x4e, 0xa7},
+				{1, aes.SUBBYTES}:     {0xbd, 0x6e, 0x7c, 0x3d, 0xf2, 0xb5, 0x77, 0x9e, 0x0b, 0x61, 0x21, 0x6e, 0x8b, 0x10, 0xb6, 0x89},
+				{1, aes.ADDROUNDKEY}:  {0xe9, 0xf7, 0x4e, 0xec, 0x02, 0x30, 0x20, 0xf6, 0x1b, 0xf2, 0xcc, 0xf2, 0x35, 0x3c, 0x21, 0xc7},
+				{1, aes.MIXCOLUMNS}:   {0x54, 0xd9, 0x90, 0xa1, 0x6b, 0xa0, 0x9a, 0xb5, 0x96, 0xbb, 0xf4, 0x0e, 0xa1, 0x11, 0x70, 0x2f},
+				{10, aes.ADDROUNDKEY}: data,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		// loop variable c will be captured by reference, so we shadow it with a new variable also
+		// called c
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			seen := make(map[traceStep][16]byte)
+			steps := 0
+			result, err := aes.RijndaelTrace(key, c.encrypt, 10, c.input, func(round int, step aes.Step, state [16]byte) {
+				seen[traceStep{round, step}] = state
+				steps++
+			})
+			if err != nil {
+				t.Fatalf("Want nil got %v", err)
+			}
+			if !bytes.Equal(result[:], c.output[:]) {
+				t.Errorf("Want %v got %v", c.output, result)
+			}
+			// 1 initial AddRoundKey, 9 full rounds of 4 steps, and a final round of 3 steps
+			if steps != 1+9*4+3 {
+				t.Errorf("Want %d steps got %d", 1+9*4+3, steps)
+			}
+			for s, want := range c.expected {
+				got, ok := seen[s]
+				if !ok {
+					t.Errorf("Round %d %v: missing", s.round, s.step)
+				} else if !bytes.Equal(got[:], want[:]) {
+					t.Errorf("Round %d %v: want %v got %v", s.round, s.step, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRijndaelRounds(t *testing.T) {
+	rng := rand.New(rand.NewSource(6))
+	key := randomKey(t, rng, 16)
+
+	// Full rounds matches Rijndael
+	var data [16]byte
+	rng.Read(data[:])
+	want, err := aes.Rijndael(key, true, data)
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	got, err := aes.RijndaelRounds(key, true, 10, data)
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	if !bytes.Equal(want[:], got[:]) {
+		t.Errorf("Want %v got %v", want, got)
+	}
+
+	// Reduced rounds round-trips
+	for rounds := 1; rounds <= 10; rounds++ {
+		encrypted, err := aes.RijndaelRounds(key, true, rounds, data)
+		if err != nil {
+			t.Fatalf("Want nil got %v", err)
+		}
+		decrypted, err := aes.RijndaelRounds(key, false, rounds, *encrypted)
+		if err != nil {
+			t.Fatalf("Want nil got %v", err)
+		}
+		if !bytes.Equal(decrypted[:], data[:]) {
+			t.Errorf("%d rounds: want %v got %v", rounds, data, decrypted)
+		}
+	}
+
+	// The integral property the Square attack is built on: over a set of 256 plaintexts which
+	// differ only in one byte, the 3-round outputs XOR to zero.
+	var sum [16]byte
+	for i := 0; i < 256; i++ {
+		data[0] = byte(i)
+		encrypted, err := aes.RijndaelRounds(key, true, 3, data)
+		if err != nil {
+			t.Fatalf("Want nil got %v", err)
+		}
+		for j := range sum {
+			sum[j] ^= encrypted[j]
+		}
+	}
+	if sum != [16]byte{} {
+		t.Errorf("Want balanced 3-round output got %v", sum)
+	}
+
+	for _, rounds := range []int{0, 11} {
+		if _, err := aes.RijndaelRounds(key, true, rounds, data); err == nil {
+			t.Errorf("%d rounds: want err got nil", rounds)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package aes
+
+// Precomputed tables which combine SubBytes, ShiftRows and MixColumns into four lookups and XORs
+// per column. te[i][x] holds the MixColumns output column for an s-boxed byte x in row i, and
+// td[i] holds the same for the inverse cipher.
+var (
+	te [4][256]uint32
+	td [4][256]uint32
+)
+
+func init() {
+	for x := 0; x < 256; x++ {
+		s := sboxEnc[x]
+		w := uint32(gfmult(2, s))<<24 | uint32(s)<<16 | uint32(s)<<8 | uint32(gfmult(3, s))
+		si := sboxDec[x]
+		wi := uint32(gfmult(14, si))<<24 | uint32(gfmult(9, si))<<16 | uint32(gfmult(13, si))<<8 | uint32(gfmult(11, si))
+		for i := 0; i < 4; i++ {
+			te[i][x] = w
+			td[i][x] = wi
+			w = w>>8 | w<<24
+			wi = wi>>8 | wi<<24
+		}
+	}
+}
+
+// roundKeyWords converts a list of round keys into columns of big-endian words.
+func roundKeyWords(roundKeys [][16]byte) []uint32 {
+	w := make([]uint32, 4*len(roundKeys))
+	for i, k := range roundKeys {
+		for j := 0; j < 4; j++ {
+			w[4*i+j] = uint32(k[4*j])<<24 | uint32(k[4*j+1])<<16 | uint32(k[4*j+2])<<8 | uint32(k[4*j+3])
+		}
+	}
+	return w
+}
+
+// invMixColumnWord applies the inverse MixColumns transformation to a single column.
+func invMixColumnWord(w uint32) uint32 {
+	return td[0][sboxEnc[w>>24]] ^ td[1][sboxEnc[(w>>16)&0xff]] ^ td[2][sboxEnc[(w>>8)&0xff]] ^ td[3][sboxEnc[w&0xff]]
+}
+
+// decryptionKeyWords converts an encryption key schedule (as produced by roundKeyWords) into the
+// schedule for the equivalent inverse cipher: the round keys are reversed, and every round key
+// except the first and last has InvMixColumns applied.
+func decryptionKeyWords(enc []uint32) []uint32 {
+	dec := make([]uint32, len(enc))
+	rounds := len(enc)/4 - 1
+	for i := 0; i <= rounds; i++ {
+		for j := 0; j < 4; j++ {
+			w := enc[4*(rounds-i)+j]
+			if i != 0 && i != rounds {
+				w = invMixColumnWord(w)
+			}
+			dec[4*i+j] = w
+		}
+	}
+	return dec
+}
+
+func stateWords(data *[16]byte) (uint32, uint32, uint32, uint32) {
+	return uint32(data[0])<<24 | uint32(data[1])<<16 | uint32(data[2])<<8 | uint32(data[3]),
+		uint32(data[4])<<24 | uint32(data[5])<<16 | uint32(data[6])<<8 | uint32(data[7]),
+		uint32(data[8])<<24 | uint32(data[9])<<16 | uint32(data[10])<<8 | uint32(data[11]),
+		uint32(data[12])<<24 | uint32(data[13])<<16 | uint32(data[14])<<8 | uint32(data[15])
+}
+
+func putStateWords(data *[16]byte, s0, s1, s2, s3 uint32) {
+	for i, w := range [4]uint32{s0, s1, s2, s3} {
+		data[4*i] = byte(w >> 24)
+		data[4*i+1] = byte(w >> 16)
+		data[4*i+2] = byte(w >> 8)
+		data[4*i+3] = byte(w)
+	}
+}
+
+// tableEncrypt encrypts a single block using the T-tables. rk must hold at least two round keys as
+// produced by roundKeyWords.
+func tableEncrypt(rk []uint32, data [16]byte) [16]byte {
+	s0, s1, s2, s3 := stateWords(&data)
+	s0 ^= rk[0]
+	s1 ^= rk[1]
+	s2 ^= rk[2]
+	s3 ^= rk[3]
+
+	rounds := len(rk)/4 - 1
+	for r := 1; r < rounds; r++ {
+		k := rk[4*r : 4*r+4]
+		t0 := te[0][s0>>24] ^ te[1][(s1>>16)&0xff] ^ te[2][(s2>>8)&0xff] ^ te[3][s3&0xff] ^ k[0]
+		t1 := te[0][s1>>24] ^ te[1][(s2>>16)&0xff] ^ te[2][(s3>>8)&0xff] ^ te[3][s0&0xff] ^ k[1]
+		t2 := te[0][s2>>24] ^ te[1][(s3>>16)&0xff] ^ te[2][(s0>>8)&0xff] ^ te[3][s1&0xff] ^ k[2]
+		t3 := te[0][s3>>24] ^ te[1][(s0>>16)&0xff] ^ te[2][(s1>>8)&0xff] ^ te[3][s2&0xff] ^ k[3]
+		s0, s1, s2, s3 = t0, t1, t2, t3
+	}
+
+	// The final round has no MixColumns
+	k := rk[4*rounds : 4*rounds+4]
+	t0 := uint32(sboxEnc[s0>>24])<<24 | uint32(sboxEnc[(s1>>16)&0xff])<<16 | uint32(sboxEnc[(s2>>8)&0xff])<<8 | uint32(sboxEnc[s3&0xff])
+	t1 := uint32(sboxEnc[s1>>24])<<24 | uint32(sboxEnc[(s2>>16)&0xff])<<16 | uint32(sboxEnc[(s3>>8)&0xff])<<8 | uint32(sboxEnc[s0&0xff])
+	t2 := uint32(sboxEnc[s2>>24])<<24 | uint32(sboxEnc[(s3>>16)&0xff])<<16 | uint32(sboxEnc[(s0>>8)&0xff])<<8 | uint32(sboxEnc[s1&0xff])
+	t3 := uint32(sboxEnc[s3>>24])<<24 | uint32(sboxEnc[(s0>>16)&0xff])<<16 | uint32(sboxEnc[(s1>>8)&0xff])<<8 | uint32(sboxEnc[s2&0xff])
+
+	var out [16]byte
+	putStateWords(&out, t0^k[0], t1^k[1], t2^k[2], t3^k[3])
+	return out
+}
+
+// tableDecrypt decrypts a single block using the T-tables. dk must hold at least two round keys as
+// produced by decryptionKeyWords.
+func tableDecrypt(dk []uint32, data [16]byte) [16]byte {
+	s0, s1, s2, s3 := stateWords(&data)
+	s0 ^= dk[0]
+	s1 ^= dk[1]
+	s2 ^= dk[2]
+	s3 ^= dk[3]
+
+	rounds := len(dk)/4 - 1
+	for r := 1; r < rounds; r++ {
+		k := dk[4*r : 4*r+4]
+		t0 := td[0][s0>>24] ^ td[1][(s3>>16)&0xff] ^ td[2][(s2>>8)&0xff] ^ td[3][s1&0xff] ^ k[0]
+		t1 := td[0][s1>>24] ^ td[1][(s0>>16)&0xff] ^ td[2][(s3>>8)&0xff] ^ td[3][s2&0xff] ^ k[1]
+		t2 := td[0][s2>>24] ^ td[1][(s1>>16)&0xff] ^ td[2][(s0>>8)&0xff] ^ td[3][s3&0xff] ^ k[2]
+		t3 := td[0][s3>>24] ^ td[1][(s2>>16)&0xff] ^ td[2][(s1>>8)&0xff] ^ td[3][s0&0xff] ^ k[3]
+		s0, s1, s2, s3 = t0, t1, t2, t3
+	}
+
+	// The final round has no InvMixColumns
+	k := dk[4*rounds : 4*rounds+4]
+	t0 := uint32(sboxDec[s0>>24])<<24 | uint32(sboxDec[(s3>>16)&0xff])<<16 | uint32(sboxDec[(s2>>8)&0xff])<<8 | uint32(sboxDec[s1&0xff])
+	t1 := uint32(sboxDec[s1>>24])<<24 | uint32(sboxDec[(s0>>16)&0xff])<<16 | uint32(sboxDec[(s3>>8)&0xff])<<8 | uint32(sboxDec[s2&0xff])
+	t2 := uint32(sboxDec[s2>>24])<<24 | uint32(sboxDec[(s1>>16)&0xff])<<16 | uint32(sboxDec[(s0>>8)&0xff])<<8 | uint32(sboxDec[s3&0xff])
+	t3 := uint32(sboxDec[s3>>24])<<24 | uint32(sboxDec[(s2>>16)&0xff])<<16 | uint32(sboxDec[(s1>>8)&0xff])<<8 | uint32(sboxDec[s0&0xff])
+
+	var out [16]byte
+	putStateWords(&out, t0^k[0], t1^k[1], t2^k[2], t3^k[3])
+	return out
+}
@@ -0,0 +1,113 @@
+package aes_test
+
+import (
+	"bytes"
+	"cryptopals/utils/aes"
+	"io"
+	"math/rand"
+	"testing"
+)
+
+func randomKey(t testing.TB, rng *rand.Rand, size int) aes.Key {
+	t.Helper()
+	data := make([]byte, size)
+	rng.Read(data)
+	key, err := aes.NewKey(data)
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	return key
+}
+
+func TestTableMatchesReference(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, size := range []int{16, 24, 32} {
+		for i := 0; i < 100; i++ {
+			key := randomKey(t, rng, size)
+			var data [16]byte
+			rng.Read(data[:])
+			for _, encrypt := range []bool{true, false} {
+				want, err := aes.Rijndael(aes.WithImplementation(key, aes.REFERENCE), encrypt, data)
+				if err != nil {
+					t.Fatalf("Want nil got %v", err)
+				}
+				got, err := aes.Rijndael(aes.WithImplementation(key, aes.TABLE), encrypt, data)
+				if err != nil {
+					t.Fatalf("Want nil got %v", err)
+				}
+				if !bytes.Equal(want[:], got[:]) {
+					t.Errorf("Key size %d, encrypt %v, data %v: want %v got %v", size, encrypt, data, want, got)
+				}
+			}
+		}
+	}
+}
+
+var implementations = []struct {
+	name string
+	impl aes.Implementation
+}{
+	{"Reference", aes.REFERENCE},
+	{"Table", aes.TABLE},
+	{"ConstantTime", aes.CONSTANTTIME},
+}
+
+func BenchmarkRijndael(b *testing.B) {
+	for _, i := range implementations {
+		key := aes.WithImplementation(randomKey(b, rand.New(rand.NewSource(1)), 16), i.impl)
+		b.Run(i.name, func(b *testing.B) {
+			var data [16]byte
+			b.SetBytes(16)
+			for n := 0; n < b.N; n++ {
+				if _, err := aes.Rijndael(key, true, data); err != nil {
+					b.Fatalf("Want nil got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func benchmarkEngine(b *testing.B, engine func(key aes.Key, r io.Reader) io.Reader) {
+	data := make([]byte, 4096)
+	for _, i := range implementations {
+		key := aes.WithImplementation(randomKey(b, rand.New(rand.NewSource(1)), 16), i.impl)
+		b.Run(i.name, func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for n := 0; n < b.N; n++ {
+				if _, err := io.Copy(io.Discard, engine(key, bytes.NewReader(data))); err != nil {
+					b.Fatalf("Want nil got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkEcb(b *testing.B) {
+	for _, encrypt := range []bool{true, false} {
+		encrypt := encrypt
+		name := "Decrypt"
+		if encrypt {
+			name = "Encrypt"
+		}
+		b.Run(name, func(b *testing.B) {
+			benchmarkEngine(b, func(key aes.Key, r io.Reader) io.Reader {
+				return aes.Ecb(key, encrypt, r)
+			})
+		})
+	}
+}
+
+func BenchmarkCbc(b *testing.B) {
+	for _, encrypt := range []bool{true, false} {
+		encrypt := encrypt
+		name := "Decrypt"
+		if encrypt {
+			name = "Encrypt"
+		}
+		b.Run(name, func(b *testing.B) {
+			benchmarkEngine(b, func(key aes.Key, r io.Reader) io.Reader {
+				return aes.Cbc(key, [16]byte{}, encrypt, r)
+			})
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package aes
+
+import (
+	"cryptopals/utils/channels"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// xtsBlock encrypts or decrypts a single block with the given tweak.
+func xtsBlock(key *ExpandedKey, encrypt bool, tweak [16]byte, data []byte) [16]byte {
+	var b [16]byte
+	for i := range b {
+		b[i] = data[i] ^ tweak[i]
+	}
+	b = key.Crypt(encrypt, b)
+	for i := range b {
+		b[i] ^= tweak[i]
+	}
+	return b
+}
+
+// Xts creates an AES-XTS encryption/decryption engine (depends on the value of `encrypt`) for a
+// single sector (data unit), using key1 for the data and key2 for the tweak. The sector must be at
+// least 16 bytes long; if it is not a multiple of 16 bytes, the last two blocks use ciphertext
+// stealing.
+func Xts(key1, key2 Key, sectorNumber uint64, encrypt bool, r io.Reader) *channels.Reader {
+	o := make(chan byte)
+	e := make(chan error)
+
+	// Create a goroutine which encrypts/decrypts data block by block. The last full block is held
+	// back until we know whether it is followed by a partial block.
+	go func() {
+		defer close(e)
+		defer close(o)
+		dataKey, err := Expand(key1)
+		if err != nil {
+			e <- err
+			return
+		}
+		tweakKey, err := Expand(key2)
+		if err != nil {
+			e <- err
+			return
+		}
+		var sector [16]byte
+		binary.LittleEndian.PutUint64(sector[:8], sectorNumber)
+		tweak := tweakKey.Encrypt(sector)
+
+		send := func(b []byte) {
+			for _, c := range b {
+				o <- c
+			}
+		}
+
+		var pending []byte
+		buf := make([]byte, 16)
+		for {
+			count, err := r.Read(buf)
+			pending = append(pending, buf[:count]...)
+			for len(pending) >= 32 {
+				out := xtsBlock(dataKey, encrypt, tweak, pending)
+				send(out[:])
+				tweak = doubleLE(tweak)
+				pending = pending[16:]
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				e <- err
+				return
+			}
+		}
+
+		switch {
+		case len(pending) < 16:
+			e <- fmt.Errorf("XTS requires at least 16 bytes of data")
+			return
+		case len(pending) == 16:
+			out := xtsBlock(dataKey, encrypt, tweak, pending)
+			send(out[:])
+		default:
+			// Ciphertext stealing: the final partial block borrows the tail of the previous block's
+			// output, and the two are emitted in swapped order.
+			partial := pending[16:]
+			first, second := tweak, doubleLE(tweak)
+			if !encrypt {
+				first, second = second, first
+			}
+			cc := xtsBlock(dataKey, encrypt, first, pending)
+			var pp [16]byte
+			copy(pp[:], partial)
+			copy(pp[len(partial):], cc[len(partial):])
+			out := xtsBlock(dataKey, encrypt, second, pp[:])
+			send(out[:])
+			send(cc[:len(partial)])
+		}
+		e <- io.EOF
+	}()
+
+	return channels.NewReader(o, e)
+}
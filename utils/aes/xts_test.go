@@ -0,0 +1,127 @@
+package aes_test
+
+import (
+	"bytes"
+	"cryptopals/utils/aes"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestXts(t *testing.T) {
+	// IEEE 1619-2007, Annex B
+	cases := []struct {
+		name       string
+		key1       string
+		key2       string
+		sector     uint64
+		plaintext  string
+		ciphertext string
+	}{
+		{
+			name:       "Vector1",
+			key1:       "00000000000000000000000000000000",
+			key2:       "00000000000000000000000000000000",
+			sector:     0,
+			plaintext:  strings.Repeat("00", 32),
+			ciphertext: "917cf69ebd68b2ec9b9fe9a3eadda692cd43d2f59598ed858c02c2652fbf922e",
+		},
+		{
+			name:       "Vector2",
+			key1:       "11111111111111111111111111111111",
+			key2:       "22222222222222222222222222222222",
+			sector:     0x3333333333,
+			plaintext:  strings.Repeat("44", 32),
+			ciphertext: "c454185e6a16936e39334038acef838bfb186fff7480adc4289382ecd6d394f0",
+		},
+		{
+			name:       "Vector3",
+			key1:       "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0",
+			key2:       "22222222222222222222222222222222",
+			sector:     0x3333333333,
+			plaintext:  strings.Repeat("44", 32),
+			ciphertext: "af85336b597afc1a900b2eb21ec949d292df4c047e0b21532186a5971a227a89",
+		},
+		{
+			name:       "Vector15",
+			key1:       "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0",
+			key2:       "bfbebdbcbbbab9b8b7b6b5b4b3b2b1b0",
+			sector:     0x123456789a,
+			plaintext:  "000102030405060708090a0b0c0d0e0f10",
+			ciphertext: "6c1625db4671522d3d7599601de7ca09ed",
+		},
+		{
+			name:       "Vector16",
+			key1:       "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0",
+			key2:       "bfbebdbcbbbab9b8b7b6b5b4b3b2b1b0",
+			sector:     0x123456789a,
+			plaintext:  "000102030405060708090a0b0c0d0e0f1011",
+			ciphertext: "d069444b7a7e0cab09e24447d24deb1fedbf",
+		},
+		{
+			name:       "Vector17",
+			key1:       "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0",
+			key2:       "bfbebdbcbbbab9b8b7b6b5b4b3b2b1b0",
+			sector:     0x123456789a,
+			plaintext:  "000102030405060708090a0b0c0d0e0f101112",
+			ciphertext: "e5df1351c0544ba1350b3363cd8ef4beedbf9d",
+		},
+		{
+			name:       "Vector18",
+			key1:       "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0",
+			key2:       "bfbebdbcbbbab9b8b7b6b5b4b3b2b1b0",
+			sector:     0x123456789a,
+			plaintext:  "000102030405060708090a0b0c0d0e0f10111213",
+			ciphertext: "9d84c813f719aa2c7be3f66171c7c5c2edbf9dac",
+		},
+	}
+
+	for _, c := range cases {
+		// loop variable c will be captured by reference, so we shadow it with a new variable also
+		// called c
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			key1, err := aes.NewKey(unhex(t, c.key1))
+			if err != nil {
+				t.Fatalf("Want nil got %v", err)
+			}
+			key2, err := aes.NewKey(unhex(t, c.key2))
+			if err != nil {
+				t.Fatalf("Want nil got %v", err)
+			}
+			plaintext := unhex(t, c.plaintext)
+			ciphertext := unhex(t, c.ciphertext)
+
+			encrypted := new(bytes.Buffer)
+			_, err = io.Copy(encrypted, aes.Xts(key1, key2, c.sector, true, iotest.OneByteReader(bytes.NewReader(plaintext))))
+			if err != nil {
+				t.Errorf("Want nil got %v", err)
+			}
+			if !bytes.Equal(encrypted.Bytes(), ciphertext) {
+				t.Errorf("Want %x got %x", ciphertext, encrypted.Bytes())
+			}
+
+			// Now do it in reverse
+			decrypted := new(bytes.Buffer)
+			_, err = io.Copy(decrypted, aes.Xts(key1, key2, c.sector, false, bytes.NewReader(ciphertext)))
+			if err != nil {
+				t.Errorf("Want nil got %v", err)
+			}
+			if !bytes.Equal(decrypted.Bytes(), plaintext) {
+				t.Errorf("Want %x got %x", plaintext, decrypted.Bytes())
+			}
+		})
+	}
+}
+
+func TestXtsTooShort(t *testing.T) {
+	key := aes.NewKey128([16]byte{})
+	for _, n := range []int{0, 15} {
+		_, err := io.Copy(io.Discard, aes.Xts(key, key, 0, true, bytes.NewReader(make([]byte, n))))
+		if err == nil {
+			t.Errorf("Length %d: want err got nil", n)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package encoding
+
+import (
+	"cryptopals/utils/channels"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Ascii85Encoding represents the framing used around ascii85 data.
+type Ascii85Encoding int
+
+const (
+	// ASCII85ADOBE wraps the data in <~ and ~>, and shortens a partial final group, as in
+	// PostScript and PDF.
+	ASCII85ADOBE Ascii85Encoding = iota
+	// ASCII85BTOA wraps the data in the "xbtoa Begin" and "xbtoa End" lines written by btoa, with
+	// the data length and checksums in the trailer, and breaks lines every 78 characters.
+	ASCII85BTOA
+)
+
+const (
+	btoaHeader  = "xbtoa Begin"
+	btoaTrailer = "xbtoa End N %d %x E %x S %x R %x"
+	btoaLine    = 78
+)
+
+// btoaChecksum holds the three checksums which btoa computes over the data, including the zero
+// bytes which fill out the last group.
+type btoaChecksum struct {
+	eor, sum, rot uint32
+}
+
+func (c *btoaChecksum) add(b byte) {
+	c.eor ^= uint32(b)
+	c.sum += uint32(b) + 1
+	c.rot = c.rot<<1 | c.rot>>31
+	c.rot += uint32(b)
+}
+
+// ascii85Encode4Bytes encodes a group of 4 bytes as 5 ASCII characters.
+func ascii85Encode4Bytes(in [4]byte) [5]byte {
+	v := uint32(in[0])<<24 | uint32(in[1])<<16 | uint32(in[2])<<8 | uint32(in[3])
+	var r [5]byte
+	for i := 4; i >= 0; i-- {
+		r[i] = '!' + byte(v%85)
+		v /= 85
+	}
+	return r
+}
+
+// Ascii85Encoder creates an Ascii85Encoder with the specified framing that reads from a Reader.
+// A group of four zero bytes is written as z.
+func Ascii85Encoder(enc Ascii85Encoding, r io.Reader) *channels.Reader {
+	o := make(chan byte)
+	e := make(chan error)
+
+	// Create a goroutine which encodes data from the reader as ascii85 characters on o.
+	// If the reader returns io.EOF, the goroutine closes e and o and returns.
+	go func() {
+		defer close(e)
+		defer close(o)
+		column := 0
+		send := func(s []byte) {
+			for _, c := range s {
+				o <- c
+				if enc == ASCII85BTOA {
+					if column++; column == btoaLine {
+						o <- '\n'
+						column = 0
+					}
+				}
+			}
+		}
+		if enc == ASCII85BTOA {
+			for _, c := range []byte(btoaHeader + "\n") {
+				o <- c
+			}
+		} else {
+			o <- '<'
+			o <- '~'
+		}
+
+		var checksum btoaChecksum
+		var length int64
+		var buf [4]byte
+		for {
+			count, err := io.ReadFull(r, buf[:])
+			if count > 0 {
+				length += int64(count)
+				for i := count; i < 4; i++ {
+					buf[i] = 0
+				}
+				for _, b := range buf {
+					checksum.add(b)
+				}
+				encoded := ascii85Encode4Bytes(buf)
+				switch {
+				case count == 4 && buf == [4]byte{}:
+					send([]byte{'z'})
+				case enc == ASCII85ADOBE:
+					send(encoded[:count+1])
+				default:
+					send(encoded[:])
+				}
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			if err != nil {
+				e <- err
+				return
+			}
+		}
+
+		if enc == ASCII85BTOA {
+			if column != 0 {
+				o <- '\n'
+			}
+			trailer := fmt.Sprintf(btoaTrailer+"\n", length, length, checksum.eor, checksum.sum, checksum.rot)
+			for _, c := range []byte(trailer) {
+				o <- c
+			}
+		} else {
+			o <- '~'
+			o <- '>'
+		}
+		e <- io.EOF
+	}()
+
+	return channels.NewReader(o, e)
+}
+
+// ascii85Decode5Chars decodes a group of 5 ascii85 characters as 4 bytes.
+func ascii85Decode5Chars(in [5]byte) ([4]byte, error) {
+	var v uint64
+	for _, c := range in {
+		v = v*85 + uint64(c-'!')
+	}
+	if v > 0xffffffff {
+		return [4]byte{}, fmt.Errorf("ascii85 group %q out of range", string(in[:]))
+	}
+	return [4]byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)}, nil
+}
+
+// Ascii85Decoder creates an Ascii85Decoder with the specified framing that reads from a Reader.
+// Whitespace between characters is always skipped. For ASCII85ADOBE the leading <~ is optional
+// and decoding stops at ~>. For ASCII85BTOA the data length and checksums in the trailer are
+// checked. A malformed input is reported as a *CorruptInputError.
+func Ascii85Decoder(enc Ascii85Encoding, r io.Reader) *channels.Reader {
+	o := make(chan byte)
+	e := make(chan error)
+
+	// Create a goroutine which decodes data from the reader as bytes on o.
+	// If the reader returns io.EOF, the goroutine closes e and o and returns.
+	go func() {
+		defer close(e)
+		defer close(o)
+		scanner := newInputScanner(r, true)
+		corrupt := func(offset int64, format string, a ...interface{}) {
+			e <- &CorruptInputError{Offset: offset, Err: fmt.Errorf(format, a...)}
+		}
+
+		// expect reads the given literal from the input
+		expect := func(literal string) bool {
+			for i := 0; i < len(literal); i++ {
+				c, offset, err := scanner.next()
+				if err != nil && err != io.EOF {
+					e <- err
+					return false
+				}
+				if err == io.EOF || c != literal[i] {
+					corrupt(offset, "expected %q", literal)
+					return false
+				}
+			}
+			return true
+		}
+
+		if enc == ASCII85BTOA {
+			// The header has a space in it, so match it word by word
+			for _, word := range strings.Fields(btoaHeader) {
+				if !expect(word) {
+					return
+				}
+			}
+		}
+
+		var checksum btoaChecksum
+		var length int64
+		var held []byte // the last group, which btoa may have padded
+		var group [5]byte
+		var count int
+		var start int64 // offset of the first character in group
+		first := true
+		for {
+			c, offset, err := scanner.next()
+			if err != nil && err != io.EOF {
+				e <- err
+				return
+			}
+			if err == io.EOF {
+				corrupt(offset, "missing end of ascii85 data")
+				return
+			}
+
+			if enc == ASCII85ADOBE && c == '<' && first {
+				// < is also a digit, so it only starts the framing if ~ follows it
+				next, _, nextErr := scanner.next()
+				if nextErr != nil && nextErr != io.EOF {
+					e <- nextErr
+					return
+				}
+				if nextErr == nil {
+					if next == '~' {
+						first = false
+						continue
+					}
+					scanner.unread()
+				}
+			}
+			first = false
+
+			if (enc == ASCII85ADOBE && c == '~') || (enc == ASCII85BTOA && c == 'x') {
+				// End of the data
+				if count == 1 || (count > 0 && enc == ASCII85BTOA) {
+					corrupt(start, "incomplete ascii85 group")
+					return
+				}
+				if enc == ASCII85ADOBE {
+					for _, b := range held {
+						o <- b
+					}
+				}
+				if count > 0 {
+					for i := count; i < 5; i++ {
+						group[i] = 'u'
+					}
+					decoded, decErr := ascii85Decode5Chars(group)
+					if decErr != nil {
+						corrupt(start, "%v", decErr)
+						return
+					}
+					for _, b := range decoded[:count-1] {
+						o <- b
+					}
+				}
+				if enc == ASCII85ADOBE {
+					if !expect(">") {
+						return
+					}
+					e <- io.EOF
+					return
+				}
+				break
+			}
+
+			switch {
+			case c == 'z' && count == 0:
+				held = append(held, 0, 0, 0, 0)
+			case c >= '!' && c <= 'u':
+				if count == 0 {
+					start = offset
+				}
+				group[count] = c
+				count++
+				if count < 5 {
+					continue
+				}
+				decoded, decErr := ascii85Decode5Chars(group)
+				if decErr != nil {
+					corrupt(start, "%v", decErr)
+					return
+				}
+				held = append(held, decoded[:]...)
+				count = 0
+			default:
+				corrupt(offset, "invalid ascii85 character %q", rune(c))
+				return
+			}
+
+			// Send all but the most recent group
+			if len(held) > 4 {
+				for _, b := range held[:len(held)-4] {
+					checksum.add(b)
+					length++
+					o <- b
+				}
+				held = held[len(held)-4:]
+			}
+		}
+
+		// The rest of the input is the btoa trailer, which we have read the x of
+		var rest strings.Builder
+		rest.WriteByte('x')
+		scanner.skipWhitespace = false
+		for {
+			c, _, err := scanner.next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				e <- err
+				return
+			}
+			rest.WriteByte(c)
+		}
+		for _, b := range held {
+			checksum.add(b)
+		}
+		var n, nHex int64
+		var expected btoaChecksum
+		trailer := strings.Join(strings.Fields(rest.String()), " ")
+		if _, err := fmt.Sscanf(trailer, btoaTrailer, &n, &nHex, &expected.eor, &expected.sum, &expected.rot); err != nil {
+			corrupt(scanner.offset, "invalid btoa trailer %q", trailer)
+			return
+		}
+		if n != nHex || n-length < 0 || n-length > int64(len(held)) || (n-length+3)/4*4 != int64(len(held)) {
+			corrupt(scanner.offset, "btoa length %d does not match the data", n)
+			return
+		}
+		if checksum != expected {
+			corrupt(scanner.offset, "btoa checksum mismatch")
+			return
+		}
+		for _, b := range held[:n-length] {
+			o <- b
+		}
+		e <- io.EOF
+	}()
+
+	return channels.NewReader(o, e)
+}
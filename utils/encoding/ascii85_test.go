@@ -0,0 +1,148 @@
+package encoding_test
+
+import (
+	"bytes"
+	"cryptopals/utils/encoding"
+	stdascii85 "encoding/ascii85"
+	"errors"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestAscii85Adobe(t *testing.T) {
+	cases := []struct {
+		input  string
+		output string
+	}{
+		{"", "<~~>"},
+		{"Man ", "<~9jqo^~>"},
+		{"Man is distinguished", "<~9jqo^BlbD-BleB1DJ+*+F(f,q~>"},
+		{"\x00\x00\x00\x00\x00", "<~z!!~>"},
+		{"sure.", "<~F*2M7/c~>"},
+	}
+
+	for _, c := range cases {
+		oBuffer := new(strings.Builder)
+		if _, err := io.Copy(oBuffer, encoding.Ascii85Encoder(encoding.ASCII85ADOBE, iotest.OneByteReader(strings.NewReader(c.input)))); err != nil {
+			t.Errorf("want nil got %v", err)
+		}
+		if oBuffer.String() != c.output {
+			t.Errorf("want %v got %v", c.output, oBuffer.String())
+		}
+
+		decoded := new(strings.Builder)
+		if _, err := io.Copy(decoded, encoding.Ascii85Decoder(encoding.ASCII85ADOBE, iotest.OneByteReader(strings.NewReader(c.output)))); err != nil {
+			t.Errorf("want nil got %v", err)
+		}
+		if decoded.String() != c.input {
+			t.Errorf("want %q got %q", c.input, decoded.String())
+		}
+	}
+}
+
+func TestAscii85AdobeDifferential(t *testing.T) {
+	rng := rand.New(rand.NewSource(85))
+	for n := 0; n < 100; n++ {
+		data := make([]byte, n)
+		rng.Read(data)
+		if n%7 == 0 {
+			// Make sure some zero groups come up
+			data = append(data, 0, 0, 0, 0)
+		}
+		expected := make([]byte, stdascii85.MaxEncodedLen(len(data)))
+		expected = expected[:stdascii85.Encode(expected, data)]
+
+		oBuffer := new(bytes.Buffer)
+		if _, err := io.Copy(oBuffer, encoding.Ascii85Encoder(encoding.ASCII85ADOBE, bytes.NewReader(data))); err != nil {
+			t.Errorf("want nil got %v", err)
+		}
+		if want := "<~" + string(expected) + "~>"; oBuffer.String() != want {
+			t.Errorf("want %v got %v", want, oBuffer.String())
+		}
+	}
+}
+
+func TestAscii85Decoder(t *testing.T) {
+	// Whitespace is skipped and the leading <~ is optional
+	decoded := new(strings.Builder)
+	if _, err := io.Copy(decoded, encoding.Ascii85Decoder(encoding.ASCII85ADOBE, strings.NewReader("9jqo^Bl\nbD-BleB1DJ+*+F(f,q ~>"))); err != nil {
+		t.Errorf("want nil got %v", err)
+	}
+	if decoded.String() != "Man is distinguished" {
+		t.Errorf("want %q got %q", "Man is distinguished", decoded.String())
+	}
+
+	// < is a digit unless ~ follows it
+	decoded.Reset()
+	if _, err := io.Copy(decoded, encoding.Ascii85Decoder(encoding.ASCII85ADOBE, strings.NewReader("<<<<<~>"))); err != nil {
+		t.Errorf("want nil got %v", err)
+	}
+	if decoded.String() != "\x55\x02\x04\xbf" {
+		t.Errorf("want %q got %q", "\x55\x02\x04\xbf", decoded.String())
+	}
+
+	cases := []struct {
+		name   string
+		input  string
+		offset int64
+	}{
+		{"MissingEnd", "<~9jqo^", 7},
+		{"InvalidChar", "<~9jqo^Bl{D~>", 9},
+		{"ZInGroup", "<~9jqz^~>", 5},
+		{"SingleChar", "<~9jqo^B~>", 7},
+		{"Overflow", "<~s8W-\"~>", 2},
+	}
+	for _, c := range cases {
+		_, err := io.Copy(io.Discard, encoding.Ascii85Decoder(encoding.ASCII85ADOBE, strings.NewReader(c.input)))
+		var corrupt *encoding.CorruptInputError
+		if !errors.As(err, &corrupt) {
+			t.Errorf("%s: want a CorruptInputError got %v", c.name, err)
+		} else if corrupt.Offset != c.offset {
+			t.Errorf("%s: want offset %d got %d", c.name, c.offset, corrupt.Offset)
+		}
+	}
+}
+
+func TestAscii85Btoa(t *testing.T) {
+	rng := rand.New(rand.NewSource(85))
+	for _, n := range []int{0, 1, 3, 4, 5, 62, 63, 64, 65, 200} {
+		data := make([]byte, n)
+		rng.Read(data)
+		encoded := new(strings.Builder)
+		if _, err := io.Copy(encoded, encoding.Ascii85Encoder(encoding.ASCII85BTOA, bytes.NewReader(data))); err != nil {
+			t.Errorf("Length %d: want nil got %v", n, err)
+		}
+		lines := strings.Split(strings.TrimSuffix(encoded.String(), "\n"), "\n")
+		if lines[0] != "xbtoa Begin" || !strings.HasPrefix(lines[len(lines)-1], "xbtoa End N ") {
+			t.Errorf("Length %d: missing btoa framing in %q", n, encoded.String())
+		}
+		for _, line := range lines {
+			if len(line) > 78 {
+				t.Errorf("Length %d: line of %d characters", n, len(line))
+			}
+		}
+
+		decoded := new(bytes.Buffer)
+		if _, err := io.Copy(decoded, encoding.Ascii85Decoder(encoding.ASCII85BTOA, iotest.HalfReader(strings.NewReader(encoded.String())))); err != nil {
+			t.Errorf("Length %d: want nil got %v", n, err)
+		}
+		if !bytes.Equal(decoded.Bytes(), data) {
+			t.Errorf("Length %d: want %x got %x", n, data, decoded.Bytes())
+		}
+	}
+
+	// Flipping the data must upset the checksums
+	encoded := new(strings.Builder)
+	if _, err := io.Copy(encoded, encoding.Ascii85Encoder(encoding.ASCII85BTOA, strings.NewReader("hello, world"))); err != nil {
+		t.Errorf("want nil got %v", err)
+	}
+	corrupted := strings.Replace(encoded.String(), "xbtoa Begin\n", "xbtoa Begin\n!!!!!", 1)
+	_, err := io.Copy(io.Discard, encoding.Ascii85Decoder(encoding.ASCII85BTOA, strings.NewReader(corrupted)))
+	var corrupt *encoding.CorruptInputError
+	if !errors.As(err, &corrupt) {
+		t.Errorf("want a CorruptInputError got %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package encoding
+
+import (
+	"cryptopals/utils/channels"
+	"fmt"
+	"io"
+)
+
+// Base32Encoding represents an RFC 4648 alphabet for base32 encoding.
+type Base32Encoding int
+
+const (
+	// BASE32STANDARD uses A to Z followed by 2 to 7
+	BASE32STANDARD Base32Encoding = iota
+	// BASE32HEX uses 0 to 9 followed by A to V, which keeps the sort order of the data
+	BASE32HEX
+)
+
+// alphabet returns the 32 characters of enc.
+// enc MUST be a valid Base32Encoding
+func (enc Base32Encoding) alphabet() string {
+	switch enc {
+	case BASE32STANDARD:
+		return "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+	case BASE32HEX:
+		return "0123456789ABCDEFGHIJKLMNOPQRSTUV"
+	default:
+		panic("invalid base32 encoding")
+	}
+}
+
+// base32DecodeQuintet decodes a single character of base32.
+func base32DecodeQuintet(in byte, enc Base32Encoding) (byte, error) {
+	for i, c := range []byte(enc.alphabet()) {
+		if c == in {
+			return byte(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid base32 character %q", rune(in))
+}
+
+// base32Encode5Bytes encodes up to 5 bytes from in as a set of 8 ASCII base32 characters according
+// to enc, with = padding.
+// in MUST be between 1 and 5 bytes
+func base32Encode5Bytes(in []byte, enc Base32Encoding) [8]byte {
+	if len(in) < 1 || len(in) > 5 {
+		panic("invalid length byte slice for base32 encode")
+	}
+	var block [5]byte
+	copy(block[:], in)
+	var v uint64
+	for _, b := range block {
+		v = v<<8 | uint64(b)
+	}
+
+	alphabet := enc.alphabet()
+	r := [8]byte{'=', '=', '=', '=', '=', '=', '=', '='}
+	// Each byte of input needs enough characters to cover its 8 bits
+	chars := (len(in)*8 + 4) / 5
+	for i := 0; i < chars; i++ {
+		r[i] = alphabet[(v>>(35-5*i))&0x1f]
+	}
+	return r
+}
+
+// Base32Encoder creates a Base32Encoder with the specified encoding that reads from a Reader.
+func Base32Encoder(enc Base32Encoding, r io.Reader) *channels.Reader {
+	o := make(chan byte)
+	e := make(chan error)
+
+	// Create a goroutine which encodes data from the reader as base32 characters on o.
+	// If the reader returns io.EOF, the goroutine closes e and o and returns.
+	go func() {
+		defer close(e)
+		defer close(o)
+		buf := make([]byte, 5)
+		for {
+			count, err := io.ReadFull(r, buf)
+			if count > 0 {
+				for _, c := range base32Encode5Bytes(buf[:count], enc) {
+					o <- c
+				}
+			}
+			if err == io.ErrUnexpectedEOF {
+				err = io.EOF
+			}
+			if err != nil {
+				e <- err
+				return
+			}
+		}
+	}()
+
+	return channels.NewReader(o, e)
+}
+
+// base32DataChars maps the number of data characters in a final group of base32 to the number of
+// bytes it holds. Other counts can't come from an encoder.
+var base32DataChars = map[int]int{2: 1, 4: 2, 5: 3, 7: 4, 8: 5}
+
+// Base32Decoder creates a Base32Decoder with the specified encoding that reads from a Reader.
+// The SkipWhitespace, RequirePadding, ForbidPadding and Canonical options apply, and a malformed
+// input is reported as a *CorruptInputError.
+func Base32Decoder(enc Base32Encoding, r io.Reader, opts ...DecodeOption) *channels.Reader {
+	o := make(chan byte)
+	e := make(chan error)
+	opt := makeDecodeOptions(opts)
+
+	// Create a goroutine which decodes data from the reader as bytes on o.
+	// If the reader returns io.EOF, the goroutine closes e and o and returns.
+	go func() {
+		defer close(e)
+		defer close(o)
+		scanner := newInputScanner(r, opt.skipWhitespace)
+		var group []byte       // characters of the current group of 8
+		var positions [8]int64 // offsets of the characters in group
+		var padded bool        // whether a group with padding has been seen
+		decode := func() error {
+			// Padding may only come at the end of the group
+			data := len(group)
+			for i, c := range group {
+				if c == '=' {
+					data = i
+					break
+				}
+			}
+			for i := data; i < len(group); i++ {
+				if group[i] != '=' {
+					return &CorruptInputError{Offset: positions[i], Err: fmt.Errorf("invalid padding sequence %q", string(group))}
+				}
+			}
+			n, ok := base32DataChars[data]
+			if !ok {
+				return &CorruptInputError{Offset: positions[0], Err: fmt.Errorf("incomplete base32 sequence: %q", string(group))}
+			}
+
+			var v uint64
+			var last byte
+			for i := 0; i < 8; i++ {
+				var q byte
+				if i < data {
+					q, _ = base32DecodeQuintet(group[i], enc)
+					last = q
+				}
+				v = v<<5 | uint64(q)
+			}
+			if extra := uint(data*5 - n*8); opt.canonical && last&(1<<extra-1) != 0 {
+				return &CorruptInputError{Offset: positions[data-1], Err: fmt.Errorf("non-canonical trailing bits")}
+			}
+			for i := 0; i < n; i++ {
+				o <- byte(v >> (32 - 8*i))
+			}
+			padded = data < 8
+			group = group[:0]
+			return nil
+		}
+
+		for {
+			c, offset, err := scanner.next()
+			if err != nil {
+				if err == io.EOF && len(group) > 0 {
+					if opt.padding == paddingRequired {
+						e <- &CorruptInputError{Offset: offset, Err: fmt.Errorf("missing padding")}
+						return
+					}
+					if decErr := decode(); decErr != nil {
+						e <- decErr
+						return
+					}
+				}
+				e <- err
+				return
+			}
+			if padded {
+				e <- &CorruptInputError{Offset: offset, Err: fmt.Errorf("data after padding")}
+				return
+			}
+			if c == '=' {
+				if opt.padding == paddingForbidden {
+					e <- &CorruptInputError{Offset: offset, Err: fmt.Errorf("padding not allowed")}
+					return
+				}
+			} else if _, decErr := base32DecodeQuintet(c, enc); decErr != nil {
+				e <- &CorruptInputError{Offset: offset, Err: decErr}
+				return
+			}
+			positions[len(group)] = offset
+			group = append(group, c)
+			if len(group) == 8 {
+				if decErr := decode(); decErr != nil {
+					e <- decErr
+					return
+				}
+			}
+		}
+	}()
+
+	return channels.NewReader(o, e)
+}
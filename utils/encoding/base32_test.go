@@ -0,0 +1,142 @@
+package encoding_test
+
+import (
+	"bytes"
+	"cryptopals/utils/encoding"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestBase32(t *testing.T) {
+	// RFC 4648, section 10
+	cases := []struct {
+		input    string
+		standard string
+		hex      string
+	}{
+		{"", "", ""},
+		{"f", "MY======", "CO======"},
+		{"fo", "MZXQ====", "CPNG===="},
+		{"foo", "MZXW6===", "CPNMU==="},
+		{"foob", "MZXW6YQ=", "CPNMUOG="},
+		{"fooba", "MZXW6YTB", "CPNMUOJ1"},
+		{"foobar", "MZXW6YTBOI======", "CPNMUOJ1E8======"},
+	}
+
+	for _, c := range cases {
+		for enc, output := range map[encoding.Base32Encoding]string{encoding.BASE32STANDARD: c.standard, encoding.BASE32HEX: c.hex} {
+			oBuffer := new(strings.Builder)
+			if _, err := io.Copy(oBuffer, encoding.Base32Encoder(enc, iotest.OneByteReader(strings.NewReader(c.input)))); err != nil {
+				t.Errorf("want nil got %v", err)
+			}
+			if oBuffer.String() != output {
+				t.Errorf("want %v got %v", output, oBuffer.String())
+			}
+
+			decoded := new(strings.Builder)
+			if _, err := io.Copy(decoded, encoding.Base32Decoder(enc, iotest.OneByteReader(strings.NewReader(output)))); err != nil {
+				t.Errorf("want nil got %v", err)
+			}
+			if decoded.String() != c.input {
+				t.Errorf("want %q got %q", c.input, decoded.String())
+			}
+		}
+	}
+}
+
+func TestBase32Decoder(t *testing.T) {
+	cases := []struct {
+		name        string
+		input       string
+		opts        []encoding.DecodeOption
+		output      []byte
+		expectError bool
+		offset      int64
+	}{
+		{
+			name:   "Unpadded",
+			input:  "MZXW6YQ",
+			output: []byte("foob"),
+		},
+		{
+			name:        "RequirePadding",
+			input:       "MZXW6YQ",
+			opts:        []encoding.DecodeOption{encoding.RequirePadding()},
+			expectError: true,
+			offset:      7,
+		},
+		{
+			name:        "ForbidPadding",
+			input:       "MZXW6YQ=",
+			opts:        []encoding.DecodeOption{encoding.ForbidPadding()},
+			expectError: true,
+			offset:      7,
+		},
+		{
+			name:   "Whitespace",
+			input:  "MZXW\r\n6YTB\nOI======\n",
+			opts:   []encoding.DecodeOption{encoding.SkipWhitespace()},
+			output: []byte("foobar"),
+		},
+		{
+			name:        "InvalidChar",
+			input:       "MZXW1YTB",
+			expectError: true,
+			offset:      4,
+		},
+		{
+			name:        "ImpossibleLength",
+			input:       "MZX=====",
+			expectError: true,
+			offset:      0,
+		},
+		{
+			name:        "DataAfterPadding",
+			input:       "MY======MY======",
+			expectError: true,
+			offset:      8,
+		},
+		{
+			name:   "NonCanonicalLenient",
+			input:  "MZ======",
+			output: []byte("f"),
+		},
+		{
+			name:        "NonCanonical",
+			input:       "MZ======",
+			opts:        []encoding.DecodeOption{encoding.Canonical()},
+			expectError: true,
+			offset:      1,
+		},
+	}
+
+	for _, c := range cases {
+		// loop variable c will be captured by reference, so we shadow it with a new variable also
+		// called c
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			decoder := encoding.Base32Decoder(encoding.BASE32STANDARD, strings.NewReader(c.input), c.opts...)
+			oBuffer := new(bytes.Buffer)
+			_, err := io.Copy(oBuffer, decoder)
+			if c.expectError {
+				var corrupt *encoding.CorruptInputError
+				if !errors.As(err, &corrupt) {
+					t.Errorf("want a CorruptInputError got %v", err)
+				} else if corrupt.Offset != c.offset {
+					t.Errorf("want offset %d got %d", c.offset, corrupt.Offset)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("want nil got %v", err)
+				}
+				if !bytes.Equal(oBuffer.Bytes(), c.output) {
+					t.Errorf("want %v got %v", c.output, oBuffer.Bytes())
+				}
+			}
+		})
+	}
+}
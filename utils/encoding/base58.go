@@ -0,0 +1,148 @@
+package encoding
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"cryptopals/utils/channels"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// Base58Encoding represents a mode for base58 encoding.
+type Base58Encoding int
+
+const (
+	// BASE58 encodes the data alone.
+	BASE58 Base58Encoding = iota
+	// BASE58CHECK appends a 4 byte checksum (the start of the double SHA-256 of the data) before
+	// encoding, as in Bitcoin addresses.
+	BASE58CHECK
+)
+
+// base58Alphabet is the Bitcoin alphabet, which leaves out 0, O, I and l.
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// ErrBase58Checksum is returned when Base58Check data does not match its checksum.
+var ErrBase58Checksum = errors.New("base58 checksum mismatch")
+
+// base58Checksum returns the Base58Check checksum of data.
+func base58Checksum(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:4]
+}
+
+// base58Encode encodes data as base58. Each leading zero byte becomes a leading '1'.
+func base58Encode(data []byte) []byte {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+	var digits []byte
+	n := new(big.Int).SetBytes(data)
+	radix := big.NewInt(58)
+	digit := new(big.Int)
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, digit)
+		digits = append(digits, base58Alphabet[digit.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		digits = append(digits, '1')
+	}
+	for i, j := 0, len(digits)-1; i < j; i, j = i+1, j-1 {
+		digits[i], digits[j] = digits[j], digits[i]
+	}
+	return digits
+}
+
+// Base58Encoder creates a Base58Encoder with the specified encoding that reads from a Reader.
+// Base58 is not a block encoding, so nothing is produced until the reader is done.
+func Base58Encoder(enc Base58Encoding, r io.Reader) *channels.Reader {
+	o := make(chan byte)
+	e := make(chan error)
+
+	// Create a goroutine which encodes all the data from the reader as base58 characters on o.
+	go func() {
+		defer close(e)
+		defer close(o)
+		data := new(bytes.Buffer)
+		if _, err := io.Copy(data, r); err != nil {
+			e <- err
+			return
+		}
+		if enc == BASE58CHECK {
+			data.Write(base58Checksum(data.Bytes()))
+		}
+		for _, c := range base58Encode(data.Bytes()) {
+			o <- c
+		}
+		e <- io.EOF
+	}()
+
+	return channels.NewReader(o, e)
+}
+
+// Base58Decoder creates a Base58Decoder with the specified encoding that reads from a Reader.
+// Nothing is produced until the reader is done. With BASE58CHECK, the checksum is verified and
+// stripped, and ErrBase58Checksum is returned if it does not match. The SkipWhitespace option
+// applies, and an invalid character is reported as a *CorruptInputError.
+func Base58Decoder(enc Base58Encoding, r io.Reader, opts ...DecodeOption) *channels.Reader {
+	o := make(chan byte)
+	e := make(chan error)
+	opt := makeDecodeOptions(opts)
+
+	// Create a goroutine which decodes all the data from the reader as bytes on o.
+	go func() {
+		defer close(e)
+		defer close(o)
+		scanner := newInputScanner(r, opt.skipWhitespace)
+		n := new(big.Int)
+		radix := big.NewInt(58)
+		zeros := 0
+		leading := true
+		for {
+			c, offset, err := scanner.next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				e <- err
+				return
+			}
+			digit := bytes.IndexByte([]byte(base58Alphabet), c)
+			if digit < 0 {
+				e <- &CorruptInputError{Offset: offset, Err: fmt.Errorf("invalid base58 character %q", rune(c))}
+				return
+			}
+			if leading && digit == 0 {
+				zeros++
+				continue
+			}
+			leading = false
+			n.Mul(n, radix)
+			n.Add(n, big.NewInt(int64(digit)))
+		}
+
+		data := append(make([]byte, zeros), n.Bytes()...)
+		if enc == BASE58CHECK {
+			if len(data) < 4 {
+				e <- ErrBase58Checksum
+				return
+			}
+			payload, checksum := data[:len(data)-4], data[len(data)-4:]
+			if !bytes.Equal(base58Checksum(payload), checksum) {
+				e <- ErrBase58Checksum
+				return
+			}
+			data = payload
+		}
+		for _, b := range data {
+			o <- b
+		}
+		e <- io.EOF
+	}()
+
+	return channels.NewReader(o, e)
+}
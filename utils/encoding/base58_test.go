@@ -0,0 +1,70 @@
+package encoding_test
+
+import (
+	"bytes"
+	"cryptopals/utils/encoding"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestBase58(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		enc    encoding.Base58Encoding
+		output string
+	}{
+		{"Empty", "", encoding.BASE58, ""},
+		{"HelloWorld", hex.EncodeToString([]byte("Hello World!")), encoding.BASE58, "2NEpo7TZRRrLZSi2U"},
+		{"QuickBrownFox", hex.EncodeToString([]byte("The quick brown fox jumps over the lazy dog.")), encoding.BASE58, "USm3fpXnKG5EUBx2ndxBDMPVciP5hGey2Jh4NDv6gmeo1LkMeiKrLJUUBk6Z"},
+		{"LeadingZeros", "000000287fb4cd", encoding.BASE58, "111233QC4"},
+		{"AllZeros", "000000", encoding.BASE58, "111"},
+		{"Address", "00010966776006953d5567439e5e39f86a0d273bee", encoding.BASE58CHECK, "16UwLL9Risc3QfPqBUvKofHmBQ7wMtjvM"},
+	}
+
+	for _, c := range cases {
+		input, err := hex.DecodeString(c.input)
+		if err != nil {
+			t.Fatalf("bad test vector %q: %v", c.input, err)
+		}
+		oBuffer := new(strings.Builder)
+		if _, err := io.Copy(oBuffer, encoding.Base58Encoder(c.enc, bytes.NewReader(input))); err != nil {
+			t.Errorf("%s: want nil got %v", c.name, err)
+		}
+		if oBuffer.String() != c.output {
+			t.Errorf("%s: want %v got %v", c.name, c.output, oBuffer.String())
+		}
+
+		decoded := new(bytes.Buffer)
+		if _, err := io.Copy(decoded, encoding.Base58Decoder(c.enc, strings.NewReader(c.output))); err != nil {
+			t.Errorf("%s: want nil got %v", c.name, err)
+		}
+		if !bytes.Equal(decoded.Bytes(), input) {
+			t.Errorf("%s: want %x got %x", c.name, input, decoded.Bytes())
+		}
+	}
+}
+
+func TestBase58Decoder(t *testing.T) {
+	_, err := io.Copy(io.Discard, encoding.Base58Decoder(encoding.BASE58CHECK, strings.NewReader("16UwLL9Risc3QfPqBUvKofHmBQ7wMtjvN")))
+	if err != encoding.ErrBase58Checksum {
+		t.Errorf("want %v got %v", encoding.ErrBase58Checksum, err)
+	}
+
+	_, err = io.Copy(io.Discard, encoding.Base58Decoder(encoding.BASE58, strings.NewReader("2NEpo7TZR0rLZSi2U")))
+	var corrupt *encoding.CorruptInputError
+	if !errors.As(err, &corrupt) || corrupt.Offset != 9 {
+		t.Errorf("want a CorruptInputError at offset 9 got %v", err)
+	}
+
+	decoded := new(strings.Builder)
+	if _, err := io.Copy(decoded, encoding.Base58Decoder(encoding.BASE58, strings.NewReader("2NEpo7TZ\nRRrLZSi2U\n"), encoding.SkipWhitespace())); err != nil {
+		t.Errorf("want nil got %v", err)
+	}
+	if decoded.String() != "Hello World!" {
+		t.Errorf("want %q got %q", "Hello World!", decoded.String())
+	}
+}
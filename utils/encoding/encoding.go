@@ -1,6 +1,7 @@
 package encoding
 
 import (
+	"bytes"
 	"cryptopals/utils/channels"
 	"fmt"
 	"io"
@@ -128,8 +129,22 @@ const (
 	STANDARD Base64Encoding = iota
 	// URL uses - and _ for code points 62 and 63, respectively
 	URL
+	// RAWSTANDARD is STANDARD without = padding
+	RAWSTANDARD
+	// RAWURL is URL without = padding, as used in JWTs
+	RAWURL
 )
 
+// raw reports whether enc leaves out padding.
+func (enc Base64Encoding) raw() bool {
+	return enc == RAWSTANDARD || enc == RAWURL
+}
+
+// url reports whether enc uses the URL alphabet.
+func (enc Base64Encoding) url() bool {
+	return enc == URL || enc == RAWURL
+}
+
 // base64EncodeHextet encodes a single hextet of base64
 // in MUST be 0 to 63, or 255 (replace with padding marker)
 // enc MUST be a valid Base64Encoding
@@ -145,18 +160,18 @@ func base64EncodeHextet(in byte, enc Base64Encoding) byte {
 		return '0' + (in - 52)
 	case in == 62:
 		switch enc {
-		case STANDARD:
+		case STANDARD, RAWSTANDARD:
 			return '+'
-		case URL:
+		case URL, RAWURL:
 			return '-'
 		default:
 			panic("invalid base64 encoding")
 		}
 	case in == 63:
 		switch enc {
-		case STANDARD:
+		case STANDARD, RAWSTANDARD:
 			return '/'
-		case URL:
+		case URL, RAWURL:
 			return '_'
 		default:
 			panic("invalid base64 encoding")
@@ -210,6 +225,9 @@ func Base64Encoder(enc Base64Encoding, r io.Reader) *channels.Reader {
 			if count > 0 {
 				encoded := base64Encode3Bytes(buf[:count], enc)
 				for _, c := range encoded {
+					if c == '=' && enc.raw() {
+						continue
+					}
 					o <- c
 				}
 			}
@@ -241,6 +259,19 @@ func base64DecodeHextet(in byte) (byte, error) {
 	}
 }
 
+// base64InAlphabet reports whether the base64 character in belongs to the alphabet of enc. Letters,
+// digits and padding belong to every alphabet.
+func base64InAlphabet(in byte, enc Base64Encoding) bool {
+	switch in {
+	case '+', '/':
+		return !enc.url()
+	case '-', '_':
+		return enc.url()
+	default:
+		return true
+	}
+}
+
 // base64Decode4Chars decode up to 4 ASCII base64 characters from in as a set of up to 3 bytes.
 // in MUST be btween 2 and 4 bytes
 func base64Decode4Chars(in []byte) ([]byte, error) {
@@ -299,33 +330,235 @@ func base64Decode4Chars(in []byte) ([]byte, error) {
 	return r[:3-padding], nil
 }
 
-// Base64Decoder creates a Base64Decoder that reads from a Reader.
-func Base64Decoder(r io.Reader) *channels.Reader {
+// A DecodeOption configures a decoder.
+type DecodeOption func(*decodeOptions)
+
+type decodeOptions struct {
+	skipWhitespace bool
+	alphabet       *Base64Encoding
+	padding        paddingRule
+	canonical      bool
+}
+
+// paddingRule says whether = padding must be present in the input.
+type paddingRule int
+
+const (
+	paddingOptional paddingRule = iota
+	paddingRequired
+	paddingForbidden
+)
+
+// SkipWhitespace makes a decoder ignore spaces, tabs, carriage returns and line feeds anywhere in
+// its input, as found in line-wrapped data files.
+func SkipWhitespace() DecodeOption {
+	return func(o *decodeOptions) {
+		o.skipWhitespace = true
+	}
+}
+
+// Base64Alphabet makes a base64 decoder reject characters from any alphabet but that of enc. By
+// default the standard and URL alphabets are both accepted, even mixed in one string. A RAWSTANDARD
+// or RAWURL encoding also forbids padding.
+func Base64Alphabet(enc Base64Encoding) DecodeOption {
+	return func(o *decodeOptions) {
+		o.alphabet = &enc
+		if enc.raw() {
+			o.padding = paddingForbidden
+		}
+	}
+}
+
+// RequirePadding makes a decoder reject input which does not end in a whole padded group.
+func RequirePadding() DecodeOption {
+	return func(o *decodeOptions) {
+		o.padding = paddingRequired
+	}
+}
+
+// ForbidPadding makes a decoder reject any padding characters.
+func ForbidPadding() DecodeOption {
+	return func(o *decodeOptions) {
+		o.padding = paddingForbidden
+	}
+}
+
+// Canonical makes a decoder reject input with non-zero bits left over in its final character,
+// so that every byte string has exactly one accepted encoding.
+func Canonical() DecodeOption {
+	return func(o *decodeOptions) {
+		o.canonical = true
+	}
+}
+
+func makeDecodeOptions(opts []DecodeOption) decodeOptions {
+	var o decodeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// isWhitespace reports whether c is skipped by the SkipWhitespace option.
+func isWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// inputScanner reads a decoder's input one byte at a time, skipping whitespace if asked to and
+// keeping track of the offset of each byte.
+type inputScanner struct {
+	r              io.Reader
+	skipWhitespace bool
+	buf            []byte
+	pos            int
+	offset         int64
+	err            error
+	last           byte  // the byte most recently returned by next
+	lastOffset     int64 // the offset of last
+	pushedBack     bool  // whether next should return last again
+}
+
+func newInputScanner(r io.Reader, skipWhitespace bool) *inputScanner {
+	return &inputScanner{r: r, skipWhitespace: skipWhitespace, buf: make([]byte, 0, 1024)}
+}
+
+// next returns the next byte of input and its offset, or the error from the underlying reader
+// once its data is used up.
+func (s *inputScanner) next() (byte, int64, error) {
+	if s.pushedBack {
+		s.pushedBack = false
+		return s.last, s.lastOffset, nil
+	}
+	for {
+		for s.pos < len(s.buf) {
+			c := s.buf[s.pos]
+			s.pos++
+			s.offset++
+			if !s.skipWhitespace || !isWhitespace(c) {
+				s.last, s.lastOffset = c, s.offset-1
+				return c, s.lastOffset, nil
+			}
+		}
+		if s.err != nil {
+			return 0, s.offset, s.err
+		}
+		var count int
+		count, s.err = s.r.Read(s.buf[:cap(s.buf)])
+		s.buf = s.buf[:count]
+		s.pos = 0
+	}
+}
+
+// unread pushes back the byte most recently returned by next, so that the following call to next
+// returns it again. It must only be called after next has returned a byte.
+func (s *inputScanner) unread() {
+	s.pushedBack = true
+}
+
+// A CorruptInputError reports malformed encoded input, along with the offset of the offending
+// byte in the input.
+type CorruptInputError struct {
+	Offset int64
+	Err    error
+}
+
+func (e *CorruptInputError) Error() string {
+	return fmt.Sprintf("%v at input byte %d", e.Err, e.Offset)
+}
+
+// Unwrap returns the underlying error.
+func (e *CorruptInputError) Unwrap() error {
+	return e.Err
+}
+
+// Base64Decoder creates a Base64Decoder that reads from a Reader. Characters are gathered into
+// groups of 4 however the underlying reader splits them up, and a malformed input is reported as a
+// *CorruptInputError.
+func Base64Decoder(r io.Reader, opts ...DecodeOption) *channels.Reader {
 	o := make(chan byte)
 	e := make(chan error)
+	opt := makeDecodeOptions(opts)
 
 	// Create a goroutine which decodes data from the reader as bytes on o.
 	// If the reader returns io.EOF, the goroutine closes e and o and returns.
 	go func() {
 		defer close(e)
 		defer close(o)
-		buf := make([]byte, 4)
-		for {
-			count, err := r.Read(buf)
-			if count > 0 {
-				decoded, err := base64Decode4Chars(buf[:count])
-				if err != nil {
-					e <- err
-					return
+		var quad []byte        // characters of the current group of 4
+		var positions [4]int64 // offsets of the characters in quad
+		var padded bool        // whether a group with padding has been seen
+		decode := func() bool {
+			decoded, err := base64Decode4Chars(quad)
+			if err != nil {
+				// A group only fails to decode because of its padding, so the first = is at fault:
+				// either it comes before data, or it leaves too few characters for a whole byte.
+				bad := 0
+				if i := bytes.IndexByte(quad, '='); i >= 0 {
+					bad = i
 				}
-				for _, c := range decoded {
-					o <- c
+				e <- &CorruptInputError{Offset: positions[bad], Err: err}
+				return false
+			}
+			if opt.canonical && len(decoded) < 3 {
+				// The character after the last whole byte must not carry any more bits
+				last := len(decoded) + 1
+				hextet, _ := base64DecodeHextet(quad[last-1])
+				if hextet&(0x3f>>(2*len(decoded))) != 0 {
+					e <- &CorruptInputError{Offset: positions[last-1], Err: fmt.Errorf("non-canonical trailing bits")}
+					return false
 				}
 			}
+			for _, c := range decoded {
+				o <- c
+			}
+			padded = len(decoded) < 3 && len(quad) == 4
+			quad = quad[:0]
+			return true
+		}
+
+		scanner := newInputScanner(r, opt.skipWhitespace)
+		for {
+			c, offset, err := scanner.next()
 			if err != nil {
+				if err == io.EOF && len(quad) > 0 {
+					if len(quad) == 1 {
+						// The input was cut off after the last character
+						e <- &CorruptInputError{Offset: positions[len(quad)-1], Err: fmt.Errorf("incomplete base64 sequence: %q", string(quad))}
+						return
+					}
+					if opt.padding == paddingRequired {
+						e <- &CorruptInputError{Offset: offset, Err: fmt.Errorf("missing padding")}
+						return
+					}
+					if !decode() {
+						return
+					}
+				}
 				e <- err
 				return
 			}
+
+			if padded {
+				e <- &CorruptInputError{Offset: offset, Err: fmt.Errorf("data after padding")}
+				return
+			}
+			if _, decErr := base64DecodeHextet(c); decErr != nil && c != '=' {
+				e <- &CorruptInputError{Offset: offset, Err: decErr}
+				return
+			}
+			if opt.alphabet != nil && !base64InAlphabet(c, *opt.alphabet) {
+				e <- &CorruptInputError{Offset: offset, Err: fmt.Errorf("base64 character %q outside the chosen alphabet", rune(c))}
+				return
+			}
+			if c == '=' && opt.padding == paddingForbidden {
+				e <- &CorruptInputError{Offset: offset, Err: fmt.Errorf("padding not allowed")}
+				return
+			}
+			positions[len(quad)] = offset
+			quad = append(quad, c)
+			if len(quad) == 4 && !decode() {
+				return
+			}
 		}
 	}()
 
@@ -3,9 +3,11 @@ package encoding_test
 import (
 	"bytes"
 	"cryptopals/utils/encoding"
+	"errors"
 	"io"
 	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestHexEncoder(t *testing.T) {
@@ -167,6 +169,18 @@ func TestBase64Encoder(t *testing.T) {
 			encoding: encoding.URL,
 			output:   "-__-",
 		},
+		{
+			name:     "RawStandard",
+			input:    []byte{0xfb, 0xff, 0xfe, 0xfb},
+			encoding: encoding.RAWSTANDARD,
+			output:   "+//++w",
+		},
+		{
+			name:     "RawURL",
+			input:    []byte{0xfb, 0xff, 0xfe, 0xfb, 0xff},
+			encoding: encoding.RAWURL,
+			output:   "-__--_8",
+		},
 	}
 
 	for _, c := range cases {
@@ -299,3 +313,165 @@ func TestBase64Decoder(t *testing.T) {
 		})
 	}
 }
+
+func TestBase64DecoderWhitespace(t *testing.T) {
+	input := "Zm9v\nYmFy\r\nZm9v  Yg=\t=\n"
+	expected := []byte("foobarfoob")
+
+	// Whitespace is an error unless it is skipped
+	_, err := io.Copy(io.Discard, encoding.Base64Decoder(strings.NewReader(input)))
+	if err == nil {
+		t.Errorf("want err got nil")
+	}
+
+	// Every split of the input into reads must decode the same way
+	for _, reader := range []func(io.Reader) io.Reader{iotest.OneByteReader, iotest.HalfReader, iotest.DataErrReader} {
+		decoder := encoding.Base64Decoder(reader(strings.NewReader(input)), encoding.SkipWhitespace())
+		oBuffer := new(bytes.Buffer)
+		if _, err := io.Copy(oBuffer, decoder); err != nil {
+			t.Errorf("want nil got %v", err)
+		}
+		if !bytes.Equal(oBuffer.Bytes(), expected) {
+			t.Errorf("want %v got %v", expected, oBuffer.Bytes())
+		}
+	}
+}
+
+func TestBase64DecoderErrorOffset(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		offset int64
+	}{
+		{"InvalidChar", "Zm9v\nYm$y", 7},
+		{"DataAfterPadding", "Zm9v\nYg==\nZm9v", 10},
+		{"PaddingInMiddle", "Zm9v\nY=Fy", 6},
+		{"TooMuchPadding", "Zm9v\nY===", 6},
+		{"TruncatedPadding", "Zm9v\nY=", 6},
+		{"Incomplete", "Zm9v\nYmFy\nZ\n", 10},
+	}
+
+	for _, c := range cases {
+		decoder := encoding.Base64Decoder(iotest.OneByteReader(strings.NewReader(c.input)), encoding.SkipWhitespace())
+		_, err := io.Copy(io.Discard, decoder)
+		var corrupt *encoding.CorruptInputError
+		if !errors.As(err, &corrupt) {
+			t.Errorf("%s: want a CorruptInputError got %v", c.name, err)
+			continue
+		}
+		if corrupt.Offset != c.offset {
+			t.Errorf("%s: want offset %d got %d", c.name, c.offset, corrupt.Offset)
+		}
+	}
+}
+
+func TestBase64DecoderOptions(t *testing.T) {
+	cases := []struct {
+		name        string
+		input       string
+		opts        []encoding.DecodeOption
+		output      []byte
+		expectError bool
+	}{
+		{
+			name:   "MixedAlphabets",
+			input:  "+__+",
+			output: []byte{0xfb, 0xff, 0xfe},
+		},
+		{
+			name:        "MixedAlphabetsStandard",
+			input:       "+__+",
+			opts:        []encoding.DecodeOption{encoding.Base64Alphabet(encoding.STANDARD)},
+			expectError: true,
+		},
+		{
+			name:        "MixedAlphabetsURL",
+			input:       "+__+",
+			opts:        []encoding.DecodeOption{encoding.Base64Alphabet(encoding.URL)},
+			expectError: true,
+		},
+		{
+			name:   "URLAlphabet",
+			input:  "-__-",
+			opts:   []encoding.DecodeOption{encoding.Base64Alphabet(encoding.URL)},
+			output: []byte{0xfb, 0xff, 0xfe},
+		},
+		{
+			name:        "RequirePaddingMissing",
+			input:       "Zm9vYg",
+			opts:        []encoding.DecodeOption{encoding.RequirePadding()},
+			expectError: true,
+		},
+		{
+			name:   "RequirePaddingPresent",
+			input:  "Zm9vYg==",
+			opts:   []encoding.DecodeOption{encoding.RequirePadding()},
+			output: []byte("foob"),
+		},
+		{
+			name:        "ForbidPadding",
+			input:       "Zm9vYg==",
+			opts:        []encoding.DecodeOption{encoding.ForbidPadding()},
+			expectError: true,
+		},
+		{
+			name:   "RawURL",
+			input:  "eyJhbGciOiJIUzI1NiJ9",
+			opts:   []encoding.DecodeOption{encoding.Base64Alphabet(encoding.RAWURL)},
+			output: []byte(`{"alg":"HS256"}`),
+		},
+		{
+			name:        "RawURLPadded",
+			input:       "Zm9vYg==",
+			opts:        []encoding.DecodeOption{encoding.Base64Alphabet(encoding.RAWURL)},
+			expectError: true,
+		},
+		{
+			name:   "NonCanonicalLenient",
+			input:  "Zh==",
+			output: []byte("f"),
+		},
+		{
+			name:        "NonCanonical1Byte",
+			input:       "Zh==",
+			opts:        []encoding.DecodeOption{encoding.Canonical()},
+			expectError: true,
+		},
+		{
+			name:        "NonCanonical2Bytes",
+			input:       "Zm9",
+			opts:        []encoding.DecodeOption{encoding.Canonical()},
+			expectError: true,
+		},
+		{
+			name:   "Canonical",
+			input:  "Zm8",
+			opts:   []encoding.DecodeOption{encoding.Canonical()},
+			output: []byte("fo"),
+		},
+	}
+
+	for _, c := range cases {
+		// loop variable c will be captured by reference, so we shadow it with a new variable also
+		// called c
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			decoder := encoding.Base64Decoder(strings.NewReader(c.input), c.opts...)
+			oBuffer := new(bytes.Buffer)
+			_, err := io.Copy(oBuffer, decoder)
+			if c.expectError {
+				if err == nil {
+					t.Errorf("want err got %v, %v", err, oBuffer.Bytes())
+				}
+			} else {
+				if err != nil {
+					t.Errorf("want nil got %v", err)
+				}
+				if !bytes.Equal(oBuffer.Bytes(), c.output) {
+					t.Errorf("want %v got %v", c.output, oBuffer.Bytes())
+				}
+			}
+		})
+	}
+}
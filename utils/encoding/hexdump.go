@@ -0,0 +1,267 @@
+package encoding
+
+import (
+	"bufio"
+	"cryptopals/utils/channels"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// HexDumpStyle represents a layout for hex dumps.
+type HexDumpStyle int
+
+const (
+	// XXD lays out rows like xxd: "00000000: 4865 6c6c 6f0a  Hello."
+	XXD HexDumpStyle = iota
+	// HEXDUMPC lays out rows like hexdump -C: "00000000  48 65 6c 6c 6f 0a  |Hello.|", and ends
+	// with the total length
+	HEXDUMPC
+)
+
+// HexDumpOptions configures HexDump. Zero values select the defaults of the style.
+type HexDumpOptions struct {
+	Style HexDumpStyle
+	// Width is the number of bytes per row, 16 by default.
+	Width int
+	// Group is the number of bytes between extra spaces: 2 by default for XXD, where bytes within
+	// a group are not separated, and 8 for HEXDUMPC.
+	Group int
+	// BlockSize, if set, marks block boundaries: a | between the bytes on either side of a
+	// boundary within a row, and a line of dashes before a row which starts a block.
+	BlockSize int
+}
+
+// withDefaults fills in the defaults for zero values.
+func (opts HexDumpOptions) withDefaults() HexDumpOptions {
+	if opts.Width <= 0 {
+		opts.Width = 16
+	}
+	if opts.Group <= 0 {
+		opts.Group = 2
+		if opts.Style == HEXDUMPC {
+			opts.Group = 8
+		}
+	}
+	return opts
+}
+
+// hexDumpRow formats one row of a hex dump, holding the bytes of row from the given offset.
+func hexDumpRow(opts HexDumpOptions, offset int64, row []byte) string {
+	var line strings.Builder
+	if opts.Style == HEXDUMPC {
+		fmt.Fprintf(&line, "%08x  ", offset)
+	} else {
+		fmt.Fprintf(&line, "%08x: ", offset)
+	}
+
+	for i := 0; i < opts.Width; i++ {
+		if i > 0 {
+			switch {
+			case opts.BlockSize > 0 && (offset+int64(i))%int64(opts.BlockSize) == 0:
+				line.WriteString(" | ")
+			case opts.Style == HEXDUMPC && i%opts.Group == 0:
+				line.WriteString("  ")
+			case opts.Style == HEXDUMPC || i%opts.Group == 0:
+				line.WriteString(" ")
+			}
+		}
+		if i < len(row) {
+			line.WriteByte(hexEncodeNybble(row[i]>>4, LOWERCASE))
+			line.WriteByte(hexEncodeNybble(row[i]&0xf, LOWERCASE))
+		} else {
+			line.WriteString("  ")
+		}
+	}
+
+	ascii := make([]byte, len(row))
+	for i, b := range row {
+		ascii[i] = '.'
+		if b >= 0x20 && b <= 0x7e {
+			ascii[i] = b
+		}
+	}
+	if opts.Style == HEXDUMPC {
+		fmt.Fprintf(&line, "  |%s|\n", ascii)
+	} else {
+		fmt.Fprintf(&line, "  %s\n", ascii)
+	}
+	return line.String()
+}
+
+// HexDump creates a reader which renders the data from a Reader as a hex dump, with an offset
+// column, grouped hex bytes and an ASCII gutter in which non-printable bytes show as dots.
+func HexDump(r io.Reader, opts HexDumpOptions) *channels.Reader {
+	o := make(chan byte)
+	e := make(chan error)
+	opts = opts.withDefaults()
+
+	// Create a goroutine which formats data from the reader one row at a time.
+	// If the reader returns io.EOF, the goroutine closes e and o and returns.
+	go func() {
+		defer close(e)
+		defer close(o)
+		send := func(s string) {
+			for _, c := range []byte(s) {
+				o <- c
+			}
+		}
+
+		var offset int64
+		row := make([]byte, opts.Width)
+		for {
+			count, err := io.ReadFull(r, row)
+			if count > 0 {
+				if opts.BlockSize > 0 && offset > 0 && offset%int64(opts.BlockSize) == 0 {
+					// Measure the separator against a full row
+					send(strings.Repeat("-", len(hexDumpRow(opts, 0, row))-1) + "\n")
+				}
+				send(hexDumpRow(opts, offset, row[:count]))
+				offset += int64(count)
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			if err != nil {
+				e <- err
+				return
+			}
+		}
+		if opts.Style == HEXDUMPC && offset > 0 {
+			send(fmt.Sprintf("%08x\n", offset))
+		}
+		e <- io.EOF
+	}()
+
+	return channels.NewReader(o, e)
+}
+
+// parseHexDumpLine parses one row of a hex dump into its offset and bytes. ok is false for lines
+// which are not rows, such as block separators. repeat is true for the * which hexdump writes in
+// place of repeated rows.
+func parseHexDumpLine(line string) (offset int64, data []byte, ok bool, repeat bool, err error) {
+	line = strings.TrimRight(line, "\r\n")
+	if strings.TrimSpace(line) == "*" {
+		return 0, nil, false, true, nil
+	}
+	end := 0
+	for end < len(line) && strings.IndexByte("0123456789abcdefABCDEF", line[end]) >= 0 {
+		end++
+	}
+	if end == 0 || (end < len(line) && line[end] != ':' && line[end] != ' ') {
+		return 0, nil, false, false, nil
+	}
+	offset, err = strconv.ParseInt(line[:end], 16, 64)
+	if err != nil {
+		return 0, nil, false, false, err
+	}
+	pos := end
+	if pos < len(line) && line[pos] == ':' {
+		pos++
+	}
+
+	// The ASCII gutter holds one character per byte, after at least two spaces, and may be
+	// wrapped in | characters. Stop reading hex as soon as the rest of the line is the gutter.
+	isGutter := func(pos int) bool {
+		n := len(data)
+		for _, width := range []int{n, n + 2} {
+			start := len(line) - width
+			if start-pos < 2 || strings.Trim(line[pos:start], " ") != "" {
+				continue
+			}
+			if width == n || (line[start] == '|' && line[len(line)-1] == '|') {
+				return true
+			}
+		}
+		return false
+	}
+	for pos < len(line) {
+		if len(data) > 0 && isGutter(pos) {
+			break
+		}
+		c := line[pos]
+		if c == ' ' || c == '|' {
+			pos++
+			continue
+		}
+		if pos+1 >= len(line) {
+			return 0, nil, false, false, fmt.Errorf("odd number of hex characters in %q", line)
+		}
+		high, decErr := hexDecodeNybble(c)
+		if decErr != nil {
+			return 0, nil, false, false, decErr
+		}
+		low, decErr := hexDecodeNybble(line[pos+1])
+		if decErr != nil {
+			return 0, nil, false, false, decErr
+		}
+		data = append(data, high<<4+low)
+		pos += 2
+	}
+	return offset, data, true, false, nil
+}
+
+// ParseHexDump creates a reader which turns a hex dump, as written by xxd, hexdump -C or HexDump,
+// back into bytes. Lines which are not rows are skipped. A gap between the end of one row and the
+// offset of the next is filled with zeros, or with copies of the previous row after a *.
+func ParseHexDump(r io.Reader) *channels.Reader {
+	o := make(chan byte)
+	e := make(chan error)
+
+	// Create a goroutine which parses the dump one line at a time.
+	// If the reader returns io.EOF, the goroutine closes e and o and returns.
+	go func() {
+		defer close(e)
+		defer close(o)
+		lines := bufio.NewReader(r)
+		var offset int64
+		var previous []byte
+		repeating := false
+		for lineNumber := 1; ; lineNumber++ {
+			line, err := lines.ReadString('\n')
+			if err != nil && err != io.EOF {
+				e <- err
+				return
+			}
+			if line != "" {
+				rowOffset, data, ok, repeat, parseErr := parseHexDumpLine(line)
+				if parseErr != nil {
+					e <- fmt.Errorf("line %d: %v", lineNumber, parseErr)
+					return
+				}
+				if repeat {
+					repeating = true
+				}
+				if ok {
+					if rowOffset < offset {
+						e <- fmt.Errorf("line %d: offset %x goes backwards", lineNumber, rowOffset)
+						return
+					}
+					for i := 0; offset < rowOffset; i++ {
+						var b byte
+						if repeating && len(previous) > 0 {
+							b = previous[i%len(previous)]
+						}
+						o <- b
+						offset++
+					}
+					for _, b := range data {
+						o <- b
+					}
+					offset += int64(len(data))
+					if len(data) > 0 {
+						previous = data
+					}
+					repeating = false
+				}
+			}
+			if err == io.EOF {
+				e <- io.EOF
+				return
+			}
+		}
+	}()
+
+	return channels.NewReader(o, e)
+}
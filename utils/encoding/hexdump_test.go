@@ -0,0 +1,172 @@
+package encoding_test
+
+import (
+	"bytes"
+	"cryptopals/utils/encoding"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+var hexDumpData = []byte("Hello, world.\n\x00\x01abcdefghijklmnopqrstuvwxyz")
+
+func TestHexDump(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  []byte
+		opts   encoding.HexDumpOptions
+		output string
+	}{
+		{
+			name:   "Empty",
+			input:  []byte{},
+			output: "",
+		},
+		{
+			// xxd
+			name:  "Default",
+			input: hexDumpData,
+			output: "00000000: 4865 6c6c 6f2c 2077 6f72 6c64 2e0a 0001  Hello, world....\n" +
+				"00000010: 6162 6364 6566 6768 696a 6b6c 6d6e 6f70  abcdefghijklmnop\n" +
+				"00000020: 7172 7374 7576 7778 797a                 qrstuvwxyz\n",
+		},
+		{
+			// xxd -c 8 -g 1
+			name:  "Width8Group1",
+			input: hexDumpData[:26],
+			opts:  encoding.HexDumpOptions{Width: 8, Group: 1},
+			output: "00000000: 48 65 6c 6c 6f 2c 20 77  Hello, w\n" +
+				"00000008: 6f 72 6c 64 2e 0a 00 01  orld....\n" +
+				"00000010: 61 62 63 64 65 66 67 68  abcdefgh\n" +
+				"00000018: 69 6a                    ij\n",
+		},
+		{
+			// xxd -g 4
+			name:  "Group4",
+			input: hexDumpData,
+			opts:  encoding.HexDumpOptions{Group: 4},
+			output: "00000000: 48656c6c 6f2c2077 6f726c64 2e0a0001  Hello, world....\n" +
+				"00000010: 61626364 65666768 696a6b6c 6d6e6f70  abcdefghijklmnop\n" +
+				"00000020: 71727374 75767778 797a               qrstuvwxyz\n",
+		},
+		{
+			// hexdump -C
+			name:  "HexdumpC",
+			input: hexDumpData,
+			opts:  encoding.HexDumpOptions{Style: encoding.HEXDUMPC},
+			output: "00000000  48 65 6c 6c 6f 2c 20 77  6f 72 6c 64 2e 0a 00 01  |Hello, world....|\n" +
+				"00000010  61 62 63 64 65 66 67 68  69 6a 6b 6c 6d 6e 6f 70  |abcdefghijklmnop|\n" +
+				"00000020  71 72 73 74 75 76 77 78  79 7a                    |qrstuvwxyz|\n" +
+				"0000002a\n",
+		},
+		{
+			name:  "Blocks",
+			input: hexDumpData,
+			opts:  encoding.HexDumpOptions{BlockSize: 16},
+			output: "00000000: 4865 6c6c 6f2c 2077 6f72 6c64 2e0a 0001  Hello, world....\n" +
+				"-------------------------------------------------------------------\n" +
+				"00000010: 6162 6364 6566 6768 696a 6b6c 6d6e 6f70  abcdefghijklmnop\n" +
+				"-------------------------------------------------------------------\n" +
+				"00000020: 7172 7374 7576 7778 797a                 qrstuvwxyz\n",
+		},
+		{
+			name:   "BlocksWithinRow",
+			input:  hexDumpData[:24],
+			opts:   encoding.HexDumpOptions{Width: 24, BlockSize: 8},
+			output: "00000000: 4865 6c6c 6f2c 2077 | 6f72 6c64 2e0a 0001 | 6162 6364 6566 6768  Hello, world....abcdefgh\n",
+		},
+	}
+
+	for _, c := range cases {
+		// loop variable c will be captured by reference, so we shadow it with a new variable also
+		// called c
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			oBuffer := new(strings.Builder)
+			if _, err := io.Copy(oBuffer, encoding.HexDump(iotest.OneByteReader(bytes.NewReader(c.input)), c.opts)); err != nil {
+				t.Errorf("want nil got %v", err)
+			}
+			if oBuffer.String() != c.output {
+				t.Errorf("want\n%s\ngot\n%s", c.output, oBuffer.String())
+			}
+
+			// Every dump must parse back to the data
+			decoded := new(bytes.Buffer)
+			if _, err := io.Copy(decoded, encoding.ParseHexDump(strings.NewReader(c.output))); err != nil {
+				t.Errorf("want nil got %v", err)
+			}
+			if !bytes.Equal(decoded.Bytes(), c.input) {
+				t.Errorf("want %q got %q", c.input, decoded.Bytes())
+			}
+		})
+	}
+}
+
+func TestParseHexDump(t *testing.T) {
+	cases := []struct {
+		name        string
+		input       string
+		output      []byte
+		expectError bool
+	}{
+		{
+			// The ASCII gutter looks like hex
+			name:   "HexLikeGutter",
+			input:  "00000000: 6162 6364 6566 2020  abcdef  \n",
+			output: []byte("abcdef  "),
+		},
+		{
+			name:   "CRLF",
+			input:  "00000000: 6869 0d0a  hi..\r\n",
+			output: []byte("hi\r\n"),
+		},
+		{
+			name:   "Gap",
+			input:  "00000000: 6869  hi\n00000004: 6869  hi\n",
+			output: []byte("hi\x00\x00hi"),
+		},
+		{
+			name: "Repeat",
+			input: "00000000  61 61 61 61 61 61 61 61  61 61 61 61 61 61 61 61  |aaaaaaaaaaaaaaaa|\n" +
+				"*\n" +
+				"00000030  62                                                |b|\n" +
+				"00000031\n",
+			output: append(bytes.Repeat([]byte{'a'}, 48), 'b'),
+		},
+		{
+			name:        "Backwards",
+			input:       "00000010: 6869  hi\n00000000: 6869  hi\n",
+			expectError: true,
+		},
+		{
+			name:        "OddHex",
+			input:       "00000000: 686  hi\n",
+			expectError: true,
+		},
+	}
+
+	for _, c := range cases {
+		// loop variable c will be captured by reference, so we shadow it with a new variable also
+		// called c
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			oBuffer := new(bytes.Buffer)
+			_, err := io.Copy(oBuffer, encoding.ParseHexDump(strings.NewReader(c.input)))
+			if c.expectError {
+				if err == nil {
+					t.Errorf("want err got %v, %q", err, oBuffer.Bytes())
+				}
+			} else {
+				if err != nil {
+					t.Errorf("want nil got %v", err)
+				}
+				if !bytes.Equal(oBuffer.Bytes(), c.output) {
+					t.Errorf("want %q got %q", c.output, oBuffer.Bytes())
+				}
+			}
+		})
+	}
+}
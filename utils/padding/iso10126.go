@@ -0,0 +1,40 @@
+package padding
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"io"
+)
+
+// iso10126 pads with random bytes followed by a final byte giving the number of padding bytes.
+// Only the length byte can be checked when unpadding.
+type iso10126 struct{}
+
+func (iso10126) Pad(blockSize byte, r io.Reader) io.Reader {
+	return pad(blockSize, r, func(padCount byte) []byte {
+		fill := make([]byte, padCount)
+		if _, err := rand.Read(fill[:padCount-1]); err != nil {
+			panic(err)
+		}
+		fill[padCount-1] = padCount
+		return fill
+	})
+}
+
+func (s iso10126) Unpad(blockSize byte, r io.Reader) io.Reader {
+	return unpad(blockSize, r, s.Check)
+}
+
+// Check runs in time which only depends on the block size.
+func (iso10126) Check(blockSize byte, block []byte) (int, error) {
+	if blockSize == 0 || len(block) != int(blockSize) {
+		return 0, ErrInvalidPadding
+	}
+	n := len(block)
+	pad := int(block[n-1])
+	good := subtle.ConstantTimeLessOrEq(1, pad) & subtle.ConstantTimeLessOrEq(pad, n)
+	if good != 1 {
+		return 0, ErrInvalidPadding
+	}
+	return pad, nil
+}
@@ -0,0 +1,42 @@
+package padding
+
+import (
+	"crypto/subtle"
+	"io"
+)
+
+// iso7816 pads with a 0x80 byte followed by zero bytes, as in ISO/IEC 7816-4.
+type iso7816 struct{}
+
+func (iso7816) Pad(blockSize byte, r io.Reader) io.Reader {
+	return pad(blockSize, r, func(padCount byte) []byte {
+		fill := make([]byte, padCount)
+		fill[0] = 0x80
+		return fill
+	})
+}
+
+func (s iso7816) Unpad(blockSize byte, r io.Reader) io.Reader {
+	return unpad(blockSize, r, s.Check)
+}
+
+// Check runs in time which only depends on the block size.
+func (iso7816) Check(blockSize byte, block []byte) (int, error) {
+	if blockSize == 0 || len(block) != int(blockSize) {
+		return 0, ErrInvalidPadding
+	}
+	n := len(block)
+	good, found, pad := 0, 0, 0
+	for i := n - 1; i >= 0; i-- {
+		// The last non-zero byte must be the 0x80 marker
+		nonZero := 1 - subtle.ConstantTimeByteEq(block[i], 0)
+		first := nonZero &^ found
+		good = subtle.ConstantTimeSelect(first, subtle.ConstantTimeByteEq(block[i], 0x80), good)
+		pad = subtle.ConstantTimeSelect(first, n-i, pad)
+		found |= nonZero
+	}
+	if good != 1 {
+		return 0, ErrInvalidPadding
+	}
+	return pad, nil
+}
@@ -0,0 +1,90 @@
+package padding
+
+import (
+	"cryptopals/utils/channels"
+	"fmt"
+	"io"
+)
+
+// checkMerkleDamgard returns an error unless lengthBytes is between 1 and blockSize-1.
+func checkMerkleDamgard(blockSize int, lengthBytes int) error {
+	if lengthBytes < 1 || lengthBytes >= blockSize {
+		return fmt.Errorf("invalid Merkle-Damgård length size %d for block size %d", lengthBytes, blockSize)
+	}
+	return nil
+}
+
+// MerkleDamgard returns the padding which a Merkle-Damgård hash appends to a message of messageLen
+// bytes: a 0x80 byte, zeros, and then the message length in bits as a lengthBytes long integer,
+// so that the padded message is a multiple of blockSize bytes. SHA-1 and SHA-256 use a 64 byte
+// block with an 8 byte big-endian length, and MD4 and MD5 the same with a little-endian length.
+// It panics if lengthBytes is not between 1 and blockSize-1.
+func MerkleDamgard(messageLen uint64, blockSize int, lengthBytes int, bigEndian bool) []byte {
+	if err := checkMerkleDamgard(blockSize, lengthBytes); err != nil {
+		panic(err)
+	}
+	padLen := blockSize - int((messageLen+uint64(lengthBytes)+1)%uint64(blockSize))
+	if padLen == blockSize {
+		padLen = 0
+	}
+	glue := make([]byte, 1+padLen+lengthBytes)
+	glue[0] = 0x80
+
+	// The bit length is 67 bits wide, so the top 3 bits spill into the ninth byte
+	length := glue[1+padLen:]
+	bits := messageLen << 3
+	for i := 0; i < lengthBytes; i++ {
+		var b byte
+		switch {
+		case i < 8:
+			b = byte(bits >> (8 * i))
+		case i == 8:
+			b = byte(messageLen >> 61)
+		}
+		if bigEndian {
+			length[lengthBytes-1-i] = b
+		} else {
+			length[i] = b
+		}
+	}
+	return glue
+}
+
+// MerkleDamgardPad copies r and then appends the MerkleDamgard padding. The length in the padding
+// counts prefixLen bytes before the data in r, as when forging an extension to a message with a
+// secret prefix. If lengthBytes is not between 1 and blockSize-1, reading returns an error.
+func MerkleDamgardPad(prefixLen uint64, blockSize int, lengthBytes int, bigEndian bool, r io.Reader) io.Reader {
+	o := make(chan byte)
+	e := make(chan error)
+
+	// Create a goroutine which copies data into the channel, then adds the padding.
+	go func() {
+		defer close(e)
+		defer close(o)
+		if err := checkMerkleDamgard(blockSize, lengthBytes); err != nil {
+			e <- err
+			return
+		}
+		count := prefixLen
+		buf := make([]byte, 64)
+		for {
+			c, err := r.Read(buf)
+			for _, b := range buf[:c] {
+				o <- b
+			}
+			count += uint64(c)
+			if err != nil {
+				if err == io.EOF {
+					// Underlying reader is done. Add the padding and then we are done.
+					for _, b := range MerkleDamgard(count, blockSize, lengthBytes, bigEndian) {
+						o <- b
+					}
+				}
+				e <- err
+				return
+			}
+		}
+	}()
+
+	return channels.NewReader(o, e)
+}
@@ -0,0 +1,106 @@
+package padding_test
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"cryptopals/utils/padding"
+	"encoding"
+	"hash"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestMerkleDamgard(t *testing.T) {
+	// FIPS 180-4 section 5.1.1: "abc" is padded to 64 bytes with a bit length of 0x18
+	glue := padding.MerkleDamgard(3, 64, 8, true)
+	expected := append([]byte{0x80}, make([]byte, 60)...)
+	expected[60] = 0x18
+	if !bytes.Equal(glue, expected) {
+		t.Errorf("Want %x got %x", expected, glue)
+	}
+
+	// MD4 and MD5 put the length little-endian
+	glue = padding.MerkleDamgard(3, 64, 8, false)
+	expected[60] = 0
+	expected[53] = 0x18
+	if !bytes.Equal(glue, expected) {
+		t.Errorf("Want %x got %x", expected, glue)
+	}
+
+	// The length no longer fits after 55 bytes, so the padding spills into another block
+	for messageLen := uint64(0); messageLen < 200; messageLen++ {
+		glue := padding.MerkleDamgard(messageLen, 64, 8, true)
+		if (messageLen+uint64(len(glue)))%64 != 0 || len(glue) < 9 || len(glue) > 72 {
+			t.Errorf("Length %d: got %d bytes of padding", messageLen, len(glue))
+		}
+	}
+
+	// The bit length of a message over 2^61 bytes needs more than 64 bits
+	glue = padding.MerkleDamgard(1<<61+1, 128, 16, true)
+	if !bytes.Equal(glue[len(glue)-16:], []byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 8}) {
+		t.Errorf("Want a 67 bit length got %x", glue[len(glue)-16:])
+	}
+}
+
+// hashState returns the chaining value of a hash after it has absorbed data, from its marshaled
+// state.
+func hashState(t *testing.T, h hash.Hash, size int, data []byte) []byte {
+	t.Helper()
+	h.Write(data)
+	state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	return state[4 : 4+size]
+}
+
+func TestMerkleDamgardHashes(t *testing.T) {
+	message := []byte("comment1=cooking%20MCs;userdata=foo;comment2=%20like%20a%20pound%20of%20bacon")
+	sha1Sum := sha1.Sum(message)
+	sha256Sum := sha256.Sum256(message)
+	sha512Sum := sha512.Sum512(message)
+	cases := []struct {
+		name        string
+		h           hash.Hash
+		sum         []byte
+		blockSize   int
+		lengthBytes int
+	}{
+		{"SHA1", sha1.New(), sha1Sum[:], 64, 8},
+		{"SHA256", sha256.New(), sha256Sum[:], 64, 8},
+		{"SHA512", sha512.New(), sha512Sum[:], 128, 16},
+	}
+
+	for _, c := range cases {
+		// After the padding the state of the hash is exactly its digest of the message
+		padded := append(append([]byte{}, message...), padding.MerkleDamgard(uint64(len(message)), c.blockSize, c.lengthBytes, true)...)
+		if state := hashState(t, c.h, len(c.sum), padded); !bytes.Equal(state, c.sum) {
+			t.Errorf("%s: want %x got %x", c.name, c.sum, state)
+		}
+	}
+}
+
+func TestMerkleDamgardPad(t *testing.T) {
+	message := []byte(";admin=true")
+	for _, prefixLen := range []uint64{0, 16, 77} {
+		outBuf := new(bytes.Buffer)
+		_, err := io.Copy(outBuf, padding.MerkleDamgardPad(prefixLen, 64, 8, true, iotest.OneByteReader(bytes.NewReader(message))))
+		if err != nil {
+			t.Errorf("Want nil got %v", err)
+		}
+		expected := append(append([]byte{}, message...), padding.MerkleDamgard(prefixLen+uint64(len(message)), 64, 8, true)...)
+		if !bytes.Equal(outBuf.Bytes(), expected) {
+			t.Errorf("Prefix %d: want %x got %x", prefixLen, expected, outBuf.Bytes())
+		}
+	}
+
+	// A length that doesn't fit in the block is an error rather than a panic
+	for _, lengthBytes := range []int{0, 64} {
+		if _, err := io.Copy(io.Discard, padding.MerkleDamgardPad(0, 64, lengthBytes, true, bytes.NewReader(message))); err == nil {
+			t.Errorf("Length size %d: want err got nil", lengthBytes)
+		}
+	}
+}
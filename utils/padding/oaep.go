@@ -0,0 +1,107 @@
+package padding
+
+import (
+	"crypto"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// mgf1 generates length bytes of mask from seed, as in RFC 8017 appendix B.2.1.
+func mgf1(h hash.Hash, seed []byte, length int) []byte {
+	mask := make([]byte, 0, length+h.Size())
+	var counter [4]byte
+	for i := uint32(0); len(mask) < length; i++ {
+		binary.BigEndian.PutUint32(counter[:], i)
+		h.Reset()
+		h.Write(seed)
+		h.Write(counter[:])
+		mask = h.Sum(mask)
+	}
+	return mask[:length]
+}
+
+// xorInto XORs src into dst.
+func xorInto(dst, src []byte) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
+
+func newOaepHash(id crypto.Hash) (hash.Hash, error) {
+	if !id.Available() {
+		return nil, fmt.Errorf("hash %v is not available", id)
+	}
+	return id.New(), nil
+}
+
+// OaepPad encodes msg as a k byte OAEP block (RFC 8017 section 7.1.1) using the given hash for
+// both the label and MGF1.
+func OaepPad(k int, hash crypto.Hash, msg, label []byte, random io.Reader) ([]byte, error) {
+	h, err := newOaepHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	hLen := h.Size()
+	if len(msg) > k-2*hLen-2 {
+		return nil, ErrMessageTooLong
+	}
+
+	// EM = 00 || maskedSeed || maskedDB, where DB = lHash || PS || 01 || msg
+	em := make([]byte, k)
+	seed := em[1 : 1+hLen]
+	db := em[1+hLen:]
+	h.Write(label)
+	h.Sum(db[:0])
+	db[len(db)-len(msg)-1] = 1
+	copy(db[len(db)-len(msg):], msg)
+	if _, err := io.ReadFull(random, seed); err != nil {
+		return nil, err
+	}
+
+	xorInto(db, mgf1(h, seed, len(db)))
+	xorInto(seed, mgf1(h, db, hLen))
+	return em, nil
+}
+
+// OaepUnpad decodes an OAEP block and returns the message. The checks are made in constant time,
+// since telling apart the ways in which decoding fails opens the door to Manger's attack.
+func OaepUnpad(hash crypto.Hash, em, label []byte) ([]byte, error) {
+	h, err := newOaepHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	hLen := h.Size()
+	k := len(em)
+	if k < 2*hLen+2 {
+		return nil, ErrInvalidPadding
+	}
+	h.Write(label)
+	lHash := h.Sum(nil)
+
+	seed := make([]byte, hLen)
+	db := make([]byte, k-hLen-1)
+	copy(seed, em[1:1+hLen])
+	copy(db, em[1+hLen:])
+	xorInto(seed, mgf1(h, db, hLen))
+	xorInto(db, mgf1(h, seed, len(db)))
+
+	good := subtle.ConstantTimeByteEq(em[0], 0) & subtle.ConstantTimeCompare(db[:hLen], lHash)
+
+	// After lHash comes a run of zero bytes, then 01
+	found, index, invalid := 0, 0, 0
+	for i := hLen; i < len(db); i++ {
+		isZero := subtle.ConstantTimeByteEq(db[i], 0)
+		isOne := subtle.ConstantTimeByteEq(db[i], 1)
+		index = subtle.ConstantTimeSelect(isOne&^found, i, index)
+		found |= isOne
+		invalid |= (1 - found) & (1 - isZero)
+	}
+	good &= found &^ invalid
+	if good != 1 {
+		return nil, ErrInvalidPadding
+	}
+	return db[index+1:], nil
+}
@@ -0,0 +1,135 @@
+package padding
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrMessageTooLong is returned when a message does not fit in an RSA block of the given size.
+var ErrMessageTooLong = errors.New("message too long for RSA block size")
+
+// Pkcs1v15EncryptPad encodes msg as a k byte PKCS#1 v1.5 encryption block:
+// 00 02 || at least 8 non-zero random bytes || 00 || msg.
+func Pkcs1v15EncryptPad(k int, msg []byte, random io.Reader) ([]byte, error) {
+	if len(msg) > k-11 {
+		return nil, ErrMessageTooLong
+	}
+	em := make([]byte, k)
+	em[1] = 2
+	ps := em[2 : k-len(msg)-1]
+	if _, err := io.ReadFull(random, ps); err != nil {
+		return nil, err
+	}
+	// Replace any zero bytes in the padding string
+	var b [1]byte
+	for i := range ps {
+		for ps[i] == 0 {
+			if _, err := io.ReadFull(random, b[:]); err != nil {
+				return nil, err
+			}
+			ps[i] = b[0]
+		}
+	}
+	copy(em[k-len(msg):], msg)
+	return em, nil
+}
+
+// Pkcs1v15EncryptUnpad decodes a PKCS#1 v1.5 encryption block and returns the message. The checks
+// are made in constant time so that the result is the only thing which leaks, as it would through
+// a Bleichenbacher padding oracle.
+func Pkcs1v15EncryptUnpad(em []byte) ([]byte, error) {
+	if len(em) < 11 {
+		return nil, ErrInvalidPadding
+	}
+	good := subtle.ConstantTimeByteEq(em[0], 0) & subtle.ConstantTimeByteEq(em[1], 2)
+
+	// Find the first zero byte after the header
+	found, index := 0, 0
+	for i := 2; i < len(em); i++ {
+		isZero := subtle.ConstantTimeByteEq(em[i], 0)
+		index = subtle.ConstantTimeSelect(isZero&^found, i, index)
+		found |= isZero
+	}
+	good &= found & subtle.ConstantTimeLessOrEq(10, index)
+	if good != 1 {
+		return nil, ErrInvalidPadding
+	}
+	return em[index+1:], nil
+}
+
+// DigestInfo prefixes for the hashes supported by Pkcs1v15SignaturePad, from RFC 8017 section 9.2.
+var digestInfoPrefixes = map[crypto.Hash][]byte{
+	crypto.SHA1:   {0x30, 0x21, 0x30, 0x09, 0x06, 0x05, 0x2b, 0x0e, 0x03, 0x02, 0x1a, 0x05, 0x00, 0x04, 0x14},
+	crypto.SHA256: {0x30, 0x31, 0x30, 0x0d, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+}
+
+// Pkcs1v15SignaturePad encodes a message digest as a k byte PKCS#1 v1.5 signature block:
+// 00 01 || at least 8 FF bytes || 00 || DigestInfo(hash, digest). Only SHA-1 and SHA-256 are
+// supported.
+func Pkcs1v15SignaturePad(k int, hash crypto.Hash, digest []byte) ([]byte, error) {
+	prefix, ok := digestInfoPrefixes[hash]
+	if !ok {
+		return nil, fmt.Errorf("unsupported hash %v", hash)
+	}
+	if len(digest) != hash.Size() {
+		return nil, fmt.Errorf("digest is %d bytes, want %d", len(digest), hash.Size())
+	}
+	t := len(prefix) + len(digest)
+	if t > k-11 {
+		return nil, ErrMessageTooLong
+	}
+	em := make([]byte, k)
+	em[1] = 1
+	for i := 2; i < k-t-1; i++ {
+		em[i] = 0xff
+	}
+	copy(em[k-t:], prefix)
+	copy(em[k-len(digest):], digest)
+	return em, nil
+}
+
+// VerifyMode selects how strictly Pkcs1v15SignatureUnpad parses a signature block.
+type VerifyMode int
+
+const (
+	// STRICT requires the block to be exactly what Pkcs1v15SignaturePad produces.
+	STRICT VerifyMode = iota
+	// LENIENT behaves like a buggy verifier: it checks 00 01, a run of FF bytes, 00 and the
+	// DigestInfo prefix, then takes the digest from the following bytes and ignores whatever comes
+	// after it. This is what Bleichenbacher's e=3 signature forgery relies on.
+	LENIENT
+)
+
+// Pkcs1v15SignatureUnpad decodes a PKCS#1 v1.5 signature block and returns the hash and digest it
+// holds.
+func Pkcs1v15SignatureUnpad(em []byte, mode VerifyMode) (crypto.Hash, []byte, error) {
+	if len(em) < 3 || em[0] != 0 || em[1] != 1 {
+		return 0, nil, ErrInvalidPadding
+	}
+	i := 2
+	for i < len(em) && em[i] == 0xff {
+		i++
+	}
+	if i == len(em) || em[i] != 0 {
+		return 0, nil, ErrInvalidPadding
+	}
+	rest := em[i+1:]
+	for hash, prefix := range digestInfoPrefixes {
+		if !bytes.HasPrefix(rest, prefix) || len(rest) < len(prefix)+hash.Size() {
+			continue
+		}
+		digest := rest[len(prefix) : len(prefix)+hash.Size()]
+		if mode == STRICT {
+			expected, err := Pkcs1v15SignaturePad(len(em), hash, digest)
+			if err != nil || !bytes.Equal(em, expected) {
+				return 0, nil, ErrInvalidPadding
+			}
+		}
+		return hash, digest, nil
+	}
+	return 0, nil, ErrInvalidPadding
+}
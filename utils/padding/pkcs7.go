@@ -1,40 +1,65 @@
 package padding
 
 import (
-	"cryptopals/utils/channels"
+	"crypto/subtle"
+	"errors"
 	"io"
 )
 
 // Pkcs7 implements PKCS#7 padding with the given block size.
 func Pkcs7(blockSize byte, r io.Reader) io.Reader {
-	o := make(chan byte)
-	e := make(chan error)
-
-	// Create a goroutine which copies data into the channel block by block, padding the last block.
-	go func() {
-		defer close(e)
-		defer close(o)
-		count := byte(0)
-		buf := []byte{0}
-		for {
-			c, err := r.Read(buf)
-			if err != nil {
-				if err == io.EOF {
-					// Underlying reader is done. Add the padding and then we are done.
-					padCount := blockSize - count
-					for i := byte(0); i < padCount; i++ {
-						o <- padCount
-					}
-				}
-				e <- err
-				return
-			}
-			if c > 0 {
-				o <- buf[0]
-				count = (count + 1) % blockSize
-			}
+	return pad(blockSize, r, func(padCount byte) []byte {
+		fill := make([]byte, padCount)
+		for i := range fill {
+			fill[i] = padCount
 		}
-	}()
+		return fill
+	})
+}
+
+// ErrInvalidPadding is returned when padding is malformed: the data is not a whole number of
+// blocks, the pad length is zero or too long, or the pad bytes are inconsistent.
+var ErrInvalidPadding = errors.New("invalid padding")
+
+// Pkcs7Check validates the PKCS#7 padding on the final block of a message and returns the number of
+// padding bytes. The check does not branch on or index by the contents of block, so its timing
+// only depends on the block size.
+func Pkcs7Check(blockSize byte, block []byte) (int, error) {
+	if blockSize == 0 || len(block) != int(blockSize) {
+		return 0, ErrInvalidPadding
+	}
+	n := len(block)
+	pad := int(block[n-1])
+	good := subtle.ConstantTimeLessOrEq(1, pad) & subtle.ConstantTimeLessOrEq(pad, n)
+	for i := 0; i < n; i++ {
+		// Every byte within the last `pad` bytes must equal pad
+		inPadding := subtle.ConstantTimeLessOrEq(n-i, pad)
+		matches := subtle.ConstantTimeByteEq(block[i], byte(pad))
+		good &= subtle.ConstantTimeSelect(inPadding, matches, 1)
+	}
+	if good != 1 {
+		return 0, ErrInvalidPadding
+	}
+	return pad, nil
+}
+
+// Pkcs7Unpad strips and validates PKCS#7 padding with the given block size. The last block is held
+// back until the underlying reader is done; if its padding is invalid, the reader returns
+// ErrInvalidPadding instead of io.EOF.
+func Pkcs7Unpad(blockSize byte, r io.Reader) io.Reader {
+	return unpad(blockSize, r, Pkcs7Check)
+}
+
+type pkcs7 struct{}
+
+func (pkcs7) Pad(blockSize byte, r io.Reader) io.Reader {
+	return Pkcs7(blockSize, r)
+}
+
+func (pkcs7) Unpad(blockSize byte, r io.Reader) io.Reader {
+	return Pkcs7Unpad(blockSize, r)
+}
 
-	return channels.NewReader(o, e)
+func (pkcs7) Check(blockSize byte, block []byte) (int, error) {
+	return Pkcs7Check(blockSize, block)
 }
@@ -5,6 +5,7 @@ import (
 	"cryptopals/utils/padding"
 	"io"
 	"testing"
+	"testing/iotest"
 )
 
 func TestPkcs7(t *testing.T) {
@@ -64,3 +65,106 @@ func TestPkcs7MultiReader(t *testing.T) {
 		t.Errorf("Want %v got %v", expect4, outBuf.Bytes())
 	}
 }
+
+func TestPkcs7Unpad(t *testing.T) {
+	cases := []struct {
+		name        string
+		blockSize   byte
+		input       []byte
+		output      []byte
+		expectError bool
+	}{
+		{
+			name:      "PartialBlock",
+			blockSize: 4,
+			input:     []byte{1, 2, 3, 4, 5, 6, 2, 2},
+			output:    []byte{1, 2, 3, 4, 5, 6},
+		},
+		{
+			name:      "FullBlock",
+			blockSize: 4,
+			input:     []byte{1, 2, 3, 4, 4, 4, 4, 4},
+			output:    []byte{1, 2, 3, 4},
+		},
+		{
+			name:      "Cryptopals15",
+			blockSize: 16,
+			input:     []byte("ICE ICE BABY\x04\x04\x04\x04"),
+			output:    []byte("ICE ICE BABY"),
+		},
+		{
+			name:        "Inconsistent",
+			blockSize:   16,
+			input:       []byte("ICE ICE BABY\x01\x02\x03\x04"),
+			expectError: true,
+		},
+		{
+			name:        "WrongByte",
+			blockSize:   16,
+			input:       []byte("ICE ICE BABY\x05\x05\x05\x05"),
+			expectError: true,
+		},
+		{
+			name:        "ZeroPadding",
+			blockSize:   4,
+			input:       []byte{1, 2, 3, 0},
+			expectError: true,
+		},
+		{
+			name:        "TooLong",
+			blockSize:   4,
+			input:       []byte{5, 5, 5, 5},
+			expectError: true,
+		},
+		{
+			name:        "NotWholeBlocks",
+			blockSize:   4,
+			input:       []byte{1, 2, 3, 4, 4, 4, 4, 4, 1},
+			expectError: true,
+		},
+		{
+			name:        "Empty",
+			blockSize:   4,
+			input:       []byte{},
+			expectError: true,
+		},
+	}
+
+	for _, c := range cases {
+		// loop variable c will be captured by reference, so we shadow it with a new variable also
+		// called c
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			unpad := padding.Pkcs7Unpad(c.blockSize, iotest.HalfReader(bytes.NewReader(c.input)))
+			outBuf := new(bytes.Buffer)
+			_, err := io.Copy(outBuf, unpad)
+			if c.expectError {
+				if err != padding.ErrInvalidPadding {
+					t.Errorf("Want %v got %v", padding.ErrInvalidPadding, err)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("Want nil got %v", err)
+				}
+				if !bytes.Equal(outBuf.Bytes(), c.output) {
+					t.Errorf("Want %v got %v", c.output, outBuf.Bytes())
+				}
+			}
+		})
+	}
+}
+
+func TestPkcs7RoundTrip(t *testing.T) {
+	for n := 0; n < 40; n++ {
+		data := bytes.Repeat([]byte{0xaa}, n)
+		outBuf := new(bytes.Buffer)
+		_, err := io.Copy(outBuf, padding.Pkcs7Unpad(16, padding.Pkcs7(16, bytes.NewReader(data))))
+		if err != nil {
+			t.Errorf("Length %d: want nil got %v", n, err)
+		}
+		if !bytes.Equal(outBuf.Bytes(), data) {
+			t.Errorf("Length %d: want %v got %v", n, data, outBuf.Bytes())
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package padding_test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"cryptopals/utils/padding"
+	"math/big"
+	"testing"
+)
+
+var rsaKey *rsa.PrivateKey
+
+func testKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	if rsaKey == nil {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("Want nil got %v", err)
+		}
+		rsaKey = key
+	}
+	return rsaKey
+}
+
+// rawEncrypt applies the RSA public key operation to a k byte block.
+func rawEncrypt(key *rsa.PrivateKey, em []byte) []byte {
+	c := new(big.Int).Exp(new(big.Int).SetBytes(em), big.NewInt(int64(key.E)), key.N)
+	return c.FillBytes(make([]byte, key.Size()))
+}
+
+// rawDecrypt applies the RSA private key operation to a k byte block.
+func rawDecrypt(key *rsa.PrivateKey, c []byte) []byte {
+	m := new(big.Int).Exp(new(big.Int).SetBytes(c), key.D, key.N)
+	return m.FillBytes(make([]byte, key.Size()))
+}
+
+func TestPkcs1v15Encrypt(t *testing.T) {
+	key := testKey(t)
+	k := key.Size()
+	message := []byte("kick it, CC")
+
+	em, err := padding.Pkcs1v15EncryptPad(k, message, rand.Reader)
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	decrypted, err := rsa.DecryptPKCS1v15(nil, key, rawEncrypt(key, em))
+	if err != nil {
+		t.Errorf("Want nil got %v", err)
+	}
+	if !bytes.Equal(decrypted, message) {
+		t.Errorf("Want %q got %q", message, decrypted)
+	}
+
+	ciphertext, err := rsa.EncryptPKCS1v15(rand.Reader, &key.PublicKey, message)
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	unpadded, err := padding.Pkcs1v15EncryptUnpad(rawDecrypt(key, ciphertext))
+	if err != nil {
+		t.Errorf("Want nil got %v", err)
+	}
+	if !bytes.Equal(unpadded, message) {
+		t.Errorf("Want %q got %q", message, unpadded)
+	}
+
+	if _, err := padding.Pkcs1v15EncryptPad(k, make([]byte, k-10), rand.Reader); err != padding.ErrMessageTooLong {
+		t.Errorf("Want %v got %v", padding.ErrMessageTooLong, err)
+	}
+}
+
+func TestPkcs1v15EncryptUnpadInvalid(t *testing.T) {
+	valid := append([]byte{0, 2}, bytes.Repeat([]byte{0x55}, 8)...)
+	valid = append(valid, 0, 'h', 'i')
+	cases := []struct {
+		name  string
+		block []byte
+	}{
+		{"LeadingByte", append([]byte{1}, valid[1:]...)},
+		{"BlockType", append([]byte{0, 1}, valid[2:]...)},
+		{"ShortPaddingString", append([]byte{0, 2, 0x55, 0x55, 0x55, 0x55, 0x55, 0x55, 0x55, 0}, valid[10:]...)},
+		{"NoSeparator", bytes.Repeat([]byte{0x55}, 16)},
+		{"TooShort", valid[:10]},
+	}
+
+	if _, err := padding.Pkcs1v15EncryptUnpad(valid); err != nil {
+		t.Errorf("Want nil got %v", err)
+	}
+	for _, c := range cases {
+		if _, err := padding.Pkcs1v15EncryptUnpad(c.block); err != padding.ErrInvalidPadding {
+			t.Errorf("%s: want %v got %v", c.name, padding.ErrInvalidPadding, err)
+		}
+	}
+}
+
+func TestPkcs1v15Signature(t *testing.T) {
+	key := testKey(t)
+	message := []byte("hi mom")
+	sha1Digest := sha1.Sum(message)
+	sha256Digest := sha256.Sum256(message)
+	digests := map[crypto.Hash][]byte{
+		crypto.SHA1:   sha1Digest[:],
+		crypto.SHA256: sha256Digest[:],
+	}
+
+	for hash, digest := range digests {
+		em, err := padding.Pkcs1v15SignaturePad(key.Size(), hash, digest)
+		if err != nil {
+			t.Fatalf("%v: want nil got %v", hash, err)
+		}
+		signature, err := rsa.SignPKCS1v15(nil, key, hash, digest)
+		if err != nil {
+			t.Fatalf("%v: want nil got %v", hash, err)
+		}
+		if !bytes.Equal(rawEncrypt(key, signature), em) {
+			t.Errorf("%v: want %x got %x", hash, rawEncrypt(key, signature), em)
+		}
+
+		for _, mode := range []padding.VerifyMode{padding.STRICT, padding.LENIENT} {
+			gotHash, gotDigest, err := padding.Pkcs1v15SignatureUnpad(em, mode)
+			if err != nil {
+				t.Errorf("%v mode %d: want nil got %v", hash, mode, err)
+			}
+			if gotHash != hash || !bytes.Equal(gotDigest, digest) {
+				t.Errorf("%v mode %d: want %v %x got %v %x", hash, mode, hash, digest, gotHash, gotDigest)
+			}
+		}
+	}
+
+	if _, err := padding.Pkcs1v15SignaturePad(key.Size(), crypto.MD5, make([]byte, 16)); err == nil {
+		t.Errorf("Want an error for an unsupported hash got nil")
+	}
+}
+
+func TestPkcs1v15SignatureLenient(t *testing.T) {
+	// The layout of a forged e=3 signature: a short run of FF bytes, then the digest, then garbage
+	digest := sha1.Sum([]byte("hi mom"))
+	em, err := padding.Pkcs1v15SignaturePad(128, crypto.SHA1, digest[:])
+	if err != nil {
+		t.Fatalf("Want nil got %v", err)
+	}
+	forged := append([]byte{0, 1, 0xff, 0xff, 0xff, 0xff, 0}, em[128-35:]...)
+	forged = append(forged, bytes.Repeat([]byte{0xab}, 128-len(forged))...)
+
+	if _, _, err := padding.Pkcs1v15SignatureUnpad(forged, padding.STRICT); err != padding.ErrInvalidPadding {
+		t.Errorf("Want %v got %v", padding.ErrInvalidPadding, err)
+	}
+	hash, gotDigest, err := padding.Pkcs1v15SignatureUnpad(forged, padding.LENIENT)
+	if err != nil {
+		t.Errorf("Want nil got %v", err)
+	}
+	if hash != crypto.SHA1 || !bytes.Equal(gotDigest, digest[:]) {
+		t.Errorf("Want %v %x got %v %x", crypto.SHA1, digest, hash, gotDigest)
+	}
+
+	// Both modes still check the prefix
+	forged[4] = 0xfe
+	for _, mode := range []padding.VerifyMode{padding.STRICT, padding.LENIENT} {
+		if _, _, err := padding.Pkcs1v15SignatureUnpad(forged, mode); err != padding.ErrInvalidPadding {
+			t.Errorf("Mode %d: want %v got %v", mode, padding.ErrInvalidPadding, err)
+		}
+	}
+}
+
+func TestOaep(t *testing.T) {
+	key := testKey(t)
+	k := key.Size()
+	message := []byte("that's why I found you don't play around with the Funky Cold Medina")
+	label := []byte("label")
+
+	for _, hash := range []crypto.Hash{crypto.SHA1, crypto.SHA256} {
+		em, err := padding.OaepPad(k, hash, message, label, rand.Reader)
+		if err != nil {
+			t.Fatalf("%v: want nil got %v", hash, err)
+		}
+		decrypted, err := rsa.DecryptOAEP(hash.New(), nil, key, rawEncrypt(key, em), label)
+		if err != nil {
+			t.Errorf("%v: want nil got %v", hash, err)
+		}
+		if !bytes.Equal(decrypted, message) {
+			t.Errorf("%v: want %q got %q", hash, message, decrypted)
+		}
+
+		ciphertext, err := rsa.EncryptOAEP(hash.New(), rand.Reader, &key.PublicKey, message, label)
+		if err != nil {
+			t.Fatalf("%v: want nil got %v", hash, err)
+		}
+		em = rawDecrypt(key, ciphertext)
+		unpadded, err := padding.OaepUnpad(hash, em, label)
+		if err != nil {
+			t.Errorf("%v: want nil got %v", hash, err)
+		}
+		if !bytes.Equal(unpadded, message) {
+			t.Errorf("%v: want %q got %q", hash, message, unpadded)
+		}
+
+		if _, err := padding.OaepUnpad(hash, em, []byte("wrong label")); err != padding.ErrInvalidPadding {
+			t.Errorf("%v: want %v got %v", hash, padding.ErrInvalidPadding, err)
+		}
+		em[len(em)-1] ^= 1
+		if _, err := padding.OaepUnpad(hash, em, label); err != padding.ErrInvalidPadding {
+			t.Errorf("%v: want %v got %v", hash, padding.ErrInvalidPadding, err)
+		}
+
+		if _, err := padding.OaepPad(k, hash, make([]byte, k-2*hash.Size()-1), nil, rand.Reader); err != padding.ErrMessageTooLong {
+			t.Errorf("%v: want %v got %v", hash, padding.ErrMessageTooLong, err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package padding
+
+import (
+	"cryptopals/utils/channels"
+	"errors"
+	"io"
+)
+
+// ErrInvalidBlockSize is returned when padding or unpadding with a block size of zero.
+var ErrInvalidBlockSize = errors.New("invalid block size")
+
+// A Scheme is a block padding scheme. Pad extends a message to a whole number of blocks, Unpad
+// strips and validates the padding, and Check validates the final block of a padded message and
+// returns the number of padding bytes in it.
+type Scheme interface {
+	Pad(blockSize byte, r io.Reader) io.Reader
+	Unpad(blockSize byte, r io.Reader) io.Reader
+	Check(blockSize byte, block []byte) (int, error)
+}
+
+// Supported padding schemes
+var (
+	PKCS7    Scheme = pkcs7{}
+	ANSIX923 Scheme = ansiX923{}
+	ISO10126 Scheme = iso10126{}
+	ISO7816  Scheme = iso7816{}
+	ZERO     Scheme = zero{}
+)
+
+// pad copies r and then appends the bytes returned by fill for the number of bytes needed to
+// complete the last block, which is blockSize if the data already fills a whole number of blocks.
+func pad(blockSize byte, r io.Reader, fill func(padCount byte) []byte) io.Reader {
+	o := make(chan byte)
+	e := make(chan error)
+
+	// Create a goroutine which copies data into the channel block by block, padding the last block.
+	go func() {
+		defer close(e)
+		defer close(o)
+		if blockSize == 0 {
+			e <- ErrInvalidBlockSize
+			return
+		}
+		count := byte(0)
+		buf := []byte{0}
+		for {
+			c, err := r.Read(buf)
+			if err != nil {
+				if err == io.EOF {
+					// Underlying reader is done. Add the padding and then we are done.
+					for _, b := range fill(blockSize - count) {
+						o <- b
+					}
+				}
+				e <- err
+				return
+			}
+			if c > 0 {
+				o <- buf[0]
+				count = (count + 1) % blockSize
+			}
+		}
+	}()
+
+	return channels.NewReader(o, e)
+}
+
+// unpad copies r, holding back the last block until the underlying reader is done. The held block
+// is then validated with check and copied out without its padding. If the data is not a whole
+// number of blocks or check fails, the reader returns an error instead of io.EOF.
+func unpad(blockSize byte, r io.Reader, check func(blockSize byte, block []byte) (int, error)) io.Reader {
+	o := make(chan byte)
+	e := make(chan error)
+
+	// Create a goroutine which copies data into the channel, keeping the last block back.
+	go func() {
+		defer close(e)
+		defer close(o)
+		if blockSize == 0 {
+			e <- ErrInvalidBlockSize
+			return
+		}
+		held := make([]byte, 0, 2*int(blockSize))
+		buf := make([]byte, blockSize)
+		total := 0
+		for {
+			c, err := r.Read(buf)
+			total += c
+			held = append(held, buf[:c]...)
+			for len(held) > int(blockSize) {
+				o <- held[0]
+				held = held[1:]
+			}
+			if err != nil {
+				if err == io.EOF {
+					if total%int(blockSize) != 0 {
+						e <- ErrInvalidPadding
+						return
+					}
+					pad, padErr := check(blockSize, held)
+					if padErr != nil {
+						e <- padErr
+						return
+					}
+					for _, b := range held[:len(held)-pad] {
+						o <- b
+					}
+				}
+				e <- err
+				return
+			}
+		}
+	}()
+
+	return channels.NewReader(o, e)
+}
@@ -0,0 +1,139 @@
+package padding_test
+
+import (
+	"bytes"
+	"cryptopals/utils/padding"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+var schemes = []struct {
+	name   string
+	scheme padding.Scheme
+}{
+	{"PKCS7", padding.PKCS7},
+	{"ANSIX923", padding.ANSIX923},
+	{"ISO10126", padding.ISO10126},
+	{"ISO7816", padding.ISO7816},
+	{"ZERO", padding.ZERO},
+}
+
+func TestSchemePad(t *testing.T) {
+	data := []byte{1, 2, 3, 4, 5}
+	cases := []struct {
+		scheme padding.Scheme
+		size   byte
+		output []byte
+	}{
+		{padding.PKCS7, 4, []byte{1, 2, 3, 4, 5, 3, 3, 3}},
+		{padding.PKCS7, 5, []byte{1, 2, 3, 4, 5, 5, 5, 5, 5, 5}},
+		{padding.ANSIX923, 4, []byte{1, 2, 3, 4, 5, 0, 0, 3}},
+		{padding.ANSIX923, 5, []byte{1, 2, 3, 4, 5, 0, 0, 0, 0, 5}},
+		{padding.ISO7816, 4, []byte{1, 2, 3, 4, 5, 0x80, 0, 0}},
+		{padding.ISO7816, 5, []byte{1, 2, 3, 4, 5, 0x80, 0, 0, 0, 0}},
+		{padding.ZERO, 4, []byte{1, 2, 3, 4, 5, 0, 0, 0}},
+		{padding.ZERO, 5, []byte{1, 2, 3, 4, 5}},
+	}
+
+	for _, c := range cases {
+		outBuf := new(bytes.Buffer)
+		_, err := io.Copy(outBuf, c.scheme.Pad(c.size, bytes.NewReader(data)))
+		if err != nil {
+			t.Errorf("Want nil got %v", err)
+		}
+		if !bytes.Equal(outBuf.Bytes(), c.output) {
+			t.Errorf("Want %v got %v", c.output, outBuf.Bytes())
+		}
+	}
+
+	// ISO 10126 fill is random, so only check the length byte
+	outBuf := new(bytes.Buffer)
+	_, err := io.Copy(outBuf, padding.ISO10126.Pad(4, bytes.NewReader(data)))
+	if err != nil {
+		t.Errorf("Want nil got %v", err)
+	}
+	if outBuf.Len() != 8 || !bytes.Equal(outBuf.Bytes()[:5], data) || outBuf.Bytes()[7] != 3 {
+		t.Errorf("Want %v followed by 2 random bytes and 3 got %v", data, outBuf.Bytes())
+	}
+}
+
+func TestSchemeRoundTrip(t *testing.T) {
+	for _, s := range schemes {
+		for n := 0; n < 40; n++ {
+			// Data must not end in zero for zero padding to be unambiguous
+			data := bytes.Repeat([]byte{0xaa}, n)
+			outBuf := new(bytes.Buffer)
+			padded := s.scheme.Pad(16, bytes.NewReader(data))
+			_, err := io.Copy(outBuf, s.scheme.Unpad(16, iotest.HalfReader(padded)))
+			if err != nil {
+				t.Errorf("%s length %d: want nil got %v", s.name, n, err)
+			}
+			if !bytes.Equal(outBuf.Bytes(), data) {
+				t.Errorf("%s length %d: want %v got %v", s.name, n, data, outBuf.Bytes())
+			}
+		}
+	}
+}
+
+func TestSchemeCheck(t *testing.T) {
+	cases := []struct {
+		name   string
+		scheme padding.Scheme
+		block  []byte
+		pad    int
+		valid  bool
+	}{
+		{"ANSIX923", padding.ANSIX923, []byte{1, 2, 0, 2}, 2, true},
+		{"ANSIX923Full", padding.ANSIX923, []byte{0, 0, 0, 4}, 4, true},
+		{"ANSIX923NonZeroFill", padding.ANSIX923, []byte{1, 2, 1, 2}, 0, false},
+		{"ANSIX923ZeroLength", padding.ANSIX923, []byte{1, 2, 3, 0}, 0, false},
+		{"ANSIX923TooLong", padding.ANSIX923, []byte{0, 0, 0, 5}, 0, false},
+		{"ISO10126", padding.ISO10126, []byte{1, 7, 9, 3}, 3, true},
+		{"ISO10126ZeroLength", padding.ISO10126, []byte{1, 7, 9, 0}, 0, false},
+		{"ISO10126TooLong", padding.ISO10126, []byte{1, 7, 9, 5}, 0, false},
+		{"ISO7816", padding.ISO7816, []byte{1, 0x80, 0, 0}, 3, true},
+		{"ISO7816Last", padding.ISO7816, []byte{1, 2, 3, 0x80}, 1, true},
+		{"ISO7816Full", padding.ISO7816, []byte{0x80, 0, 0, 0}, 4, true},
+		{"ISO7816NoMarker", padding.ISO7816, []byte{1, 2, 0, 0}, 0, false},
+		{"ISO7816AllZero", padding.ISO7816, []byte{0, 0, 0, 0}, 0, false},
+		{"ZERO", padding.ZERO, []byte{1, 2, 0, 0}, 2, true},
+		{"ZERONone", padding.ZERO, []byte{1, 2, 3, 4}, 0, true},
+		{"ZEROAll", padding.ZERO, []byte{0, 0, 0, 0}, 4, true},
+		{"ShortBlock", padding.ANSIX923, []byte{0, 0, 3}, 0, false},
+	}
+
+	for _, c := range cases {
+		pad, err := c.scheme.Check(4, c.block)
+		if c.valid {
+			if err != nil {
+				t.Errorf("%s: want nil got %v", c.name, err)
+			}
+			if pad != c.pad {
+				t.Errorf("%s: want %d got %d", c.name, c.pad, pad)
+			}
+		} else if err != padding.ErrInvalidPadding {
+			t.Errorf("%s: want %v got %v", c.name, padding.ErrInvalidPadding, err)
+		}
+	}
+}
+
+func TestSchemeUnpadNotWholeBlocks(t *testing.T) {
+	for _, s := range schemes {
+		_, err := io.Copy(io.Discard, s.scheme.Unpad(4, bytes.NewReader([]byte{1, 2, 3, 4, 1})))
+		if err != padding.ErrInvalidPadding {
+			t.Errorf("%s: want %v got %v", s.name, padding.ErrInvalidPadding, err)
+		}
+	}
+}
+
+func TestSchemeZeroBlockSize(t *testing.T) {
+	for _, s := range schemes {
+		if _, err := io.Copy(io.Discard, s.scheme.Pad(0, bytes.NewReader([]byte{1, 2, 3}))); err != padding.ErrInvalidBlockSize {
+			t.Errorf("%s pad: want %v got %v", s.name, padding.ErrInvalidBlockSize, err)
+		}
+		if _, err := io.Copy(io.Discard, s.scheme.Unpad(0, bytes.NewReader([]byte{1, 2, 3}))); err != padding.ErrInvalidBlockSize {
+			t.Errorf("%s unpad: want %v got %v", s.name, padding.ErrInvalidBlockSize, err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package padding
+
+import (
+	"crypto/subtle"
+	"io"
+)
+
+// ansiX923 pads with zero bytes followed by a final byte giving the number of padding bytes.
+type ansiX923 struct{}
+
+func (ansiX923) Pad(blockSize byte, r io.Reader) io.Reader {
+	return pad(blockSize, r, func(padCount byte) []byte {
+		fill := make([]byte, padCount)
+		fill[padCount-1] = padCount
+		return fill
+	})
+}
+
+func (s ansiX923) Unpad(blockSize byte, r io.Reader) io.Reader {
+	return unpad(blockSize, r, s.Check)
+}
+
+// Check runs in time which only depends on the block size.
+func (ansiX923) Check(blockSize byte, block []byte) (int, error) {
+	if blockSize == 0 || len(block) != int(blockSize) {
+		return 0, ErrInvalidPadding
+	}
+	n := len(block)
+	pad := int(block[n-1])
+	good := subtle.ConstantTimeLessOrEq(1, pad) & subtle.ConstantTimeLessOrEq(pad, n)
+	for i := 0; i < n-1; i++ {
+		// Every byte within the last `pad` bytes apart from the length must be zero
+		inPadding := subtle.ConstantTimeLessOrEq(n-i, pad)
+		matches := subtle.ConstantTimeByteEq(block[i], 0)
+		good &= subtle.ConstantTimeSelect(inPadding, matches, 1)
+	}
+	if good != 1 {
+		return 0, ErrInvalidPadding
+	}
+	return pad, nil
+}
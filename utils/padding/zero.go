@@ -0,0 +1,39 @@
+package padding
+
+import (
+	"crypto/subtle"
+	"io"
+)
+
+// zero pads with zero bytes, adding nothing if the data already fills a whole number of blocks.
+// Unpadding strips every trailing zero byte of the last block, so it is only unambiguous for data
+// which does not end in zero.
+type zero struct{}
+
+func (zero) Pad(blockSize byte, r io.Reader) io.Reader {
+	return pad(blockSize, r, func(padCount byte) []byte {
+		if padCount == blockSize {
+			return nil
+		}
+		return make([]byte, padCount)
+	})
+}
+
+func (s zero) Unpad(blockSize byte, r io.Reader) io.Reader {
+	return unpad(blockSize, r, s.Check)
+}
+
+// Check accepts an empty block, since zero padding an empty message adds nothing. It runs in time
+// which only depends on the block size.
+func (zero) Check(blockSize byte, block []byte) (int, error) {
+	if blockSize == 0 || (len(block) != 0 && len(block) != int(blockSize)) {
+		return 0, ErrInvalidPadding
+	}
+	n := len(block)
+	found, pad := 0, 0
+	for i := n - 1; i >= 0; i-- {
+		found |= 1 - subtle.ConstantTimeByteEq(block[i], 0)
+		pad += 1 - found
+	}
+	return pad, nil
+}